@@ -1,7 +1,12 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/HanksJCTsai/goidleguard/internal/config"
@@ -13,55 +18,327 @@ import (
 type Controller struct {
 	cfg        *config.APPConfig
 	scheduler  *schedule.Scheduler
+	backend    *preventidle.Backend
+	dryRun     *preventidle.DryRun // dry-run 模式下記錄動作的後端，否則為 nil
+	journal    string              // dry-run 日誌檔路徑，由 NewController 開啟時才有值
+	activity   *preventidle.ActivityTracker
+	limiter    *preventidle.RateLimiter
+	focus      *preventidle.FocusGuard // 設定焦點視窗規則時才有值
 	healthStop chan struct{}
+	now        func() time.Time
+	rng        *rand.Rand
+	threshold  time.Duration // 本輪的閒置門檻，設定 jitter 時每次模擬後重新抽樣
+
+	// interval: auto 時由螢幕保護逾時推算的門檻，以及下次重新讀取的時間
+	timeoutProbes []preventidle.TimeoutProbe
+	autoRecheck   time.Time
+
+	mu       sync.Mutex
+	running  bool
+	asserted bool
+	interval time.Duration // 目前生效的閒置門檻基準，非 auto 時即 idlePrevention.interval
+}
+
+// DaemonStatus 描述常駐程式目前的執行狀態與各能力使用中的後端
+type DaemonStatus struct {
+	Running  bool                       `json:"running"`
+	Backends preventidle.ActiveBackends `json:"backends"`
+}
+
+// NewController 檢查自訂動作與巨集後，依 idlePrevention.backend 偵測可用後端並建立 Controller。
+// dry-run 模式下只偵測閒置來源，不建立輸入裝置或電源鎖，輸入與電源鎖改為寫入日誌。
+// 設定焦點視窗規則時另外開啟焦點來源，無法開啟則回傳錯誤。
+func NewController(cfg *config.APPConfig) (*Controller, error) {
+	if dev := cfg.IdlePrevention.Uinput.Device; dev != "" {
+		preventidle.UinputDevicePath = dev
+	}
+	if _, err := preventidle.ActionsFor(&cfg.IdlePrevention); err != nil {
+		return nil, err
+	}
+	if name := cfg.IdlePrevention.Macro; name != "" {
+		if _, err := preventidle.CompileMacro(&cfg.IdlePrevention, name); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.IdlePrevention.DryRun {
+		return newDryRunController(cfg)
+	}
+	chain, err := preventidle.SelectBackends(&cfg.IdlePrevention)
+	if err != nil {
+		return nil, err
+	}
+	return withFocus(NewControllerWithBackend(cfg, chain.Backend()))
+}
+
+// newDryRunController 只以真實的閒置來源建立 dry-run Controller，並開啟日誌檔
+func newDryRunController(cfg *config.APPConfig) (*Controller, error) {
+	chain, err := preventidle.SelectIdleBackends(&cfg.IdlePrevention)
+	if err != nil {
+		return nil, err
+	}
+	path := cfg.IdlePrevention.DryRunJournal
+	if path == "" {
+		path = preventidle.DefaultDryRunJournal
+	}
+	f, err := openJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.LogInfo("Dry run: journaling intended actions to", path)
+	c := NewControllerWithBackend(cfg, preventidle.NewDryRun(f, chain.Backend(), &cfg.IdlePrevention).Backend())
+	c.journal = path
+	c, err = withFocus(c)
+	if err != nil {
+		f.Close()
+	}
+	return c, err
+}
+
+// withFocus 在設定焦點視窗規則時為 Controller 開啟焦點來源
+func withFocus(c *Controller) (*Controller, error) {
+	cfg := c.cfg
+	if preventidle.FocusRulesConfigured(cfg.IdlePrevention.Focus) {
+		src, err := preventidle.OpenFocusSource(cfg.IdlePrevention.X11.Display)
+		if err != nil {
+			return nil, fmt.Errorf("idlePrevention.focus: %w", err)
+		}
+		c.focus = preventidle.NewFocusGuard(src, cfg.IdlePrevention.Focus)
+	}
+	return c, nil
 }
 
-func NewController(cfg *config.APPConfig) *Controller {
-	return &Controller{
-		cfg:        cfg,
-		scheduler:  schedule.InitialScheduler(cfg),
+// openJournal 以附加模式開啟 dry-run 日誌檔
+func openJournal(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open dry-run journal failed: %w", err)
+	}
+	return f, nil
+}
+
+// NewControllerWithBackend 以指定的後端建立 Controller，方便測試時注入假後端
+func NewControllerWithBackend(cfg *config.APPConfig, backend *preventidle.Backend) *Controller {
+	limiter := preventidle.NewRateLimiter(cfg.IdlePrevention.RateLimit)
+	c := &Controller{
+		cfg:     cfg,
+		backend: backend,
+		// 每個實際送出的事件才扣除頻率配額
+		activity:   preventidle.NewActivityTracker(limiter.Limit(backend.Injector), backend.Idle),
+		limiter:    limiter,
 		healthStop: make(chan struct{}),
+		now:        time.Now,
+		rng:        preventidle.NewRand(cfg.IdlePrevention.Jitter.Seed),
 	}
+	if d, ok := backend.Injector.(*preventidle.DryRun); ok {
+		c.dryRun = d
+	}
+	c.interval = cfg.IdlePrevention.Interval
+	if cfg.IdlePrevention.IntervalAuto {
+		c.timeoutProbes = preventidle.ScreensaverTimeoutProbes(&cfg.IdlePrevention)
+		c.refreshAutoInterval(c.now())
+	}
+	c.scheduler = c.newScheduler()
+	c.threshold = c.nextThreshold()
+	return c
+}
+
+// newScheduler 建立排程器；設定 jitter 時每次觸發的間隔會隨機調整
+func (c *Controller) newScheduler() *schedule.Scheduler {
+	s := schedule.InitialScheduler(c.cfg)
+	if percent := c.cfg.IdlePrevention.Jitter.IntervalPercent; percent > 0 {
+		s.Jitter = func(interval time.Duration) time.Duration {
+			return preventidle.JitterInterval(interval, percent, c.rng)
+		}
+	}
+	return s
+}
+
+// nextThreshold 抽樣下一輪的閒置門檻
+func (c *Controller) nextThreshold() time.Duration {
+	return preventidle.JitterInterval(c.currentInterval(), c.cfg.IdlePrevention.Jitter.IntervalPercent, c.rng)
+}
+
+// currentInterval 回傳目前生效的閒置門檻基準
+func (c *Controller) currentInterval() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.interval
+}
+
+// refreshAutoInterval 在 interval: auto 且到了重新檢查的時間時，重新讀取螢幕保護逾時並更新門檻
+func (c *Controller) refreshAutoInterval(now time.Time) {
+	if !c.cfg.IdlePrevention.IntervalAuto || now.Before(c.autoRecheck) {
+		return
+	}
+	c.autoRecheck = now.Add(preventidle.AutoIntervalRecheck(&c.cfg.IdlePrevention))
+
+	interval, source, err := preventidle.AutoInterval(c.cfg, c.timeoutProbes)
+	if err != nil {
+		logger.LogError("Auto interval:", err)
+	}
+	c.mu.Lock()
+	changed := interval != c.interval
+	c.interval = interval
+	c.mu.Unlock()
+	if changed {
+		logger.LogInfo(fmt.Sprintf("Auto interval: idle threshold set to %v (%s)", interval, source))
+		c.threshold = c.nextThreshold()
+	}
+}
+
+// Status 回傳常駐程式目前的狀態
+func (c *Controller) Status() DaemonStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return DaemonStatus{Running: c.running, Backends: c.backend.Active()}
 }
+
 func (c *Controller) StartDaemon() {
-	logger.LogInfo("StartDaemon: will wait for idle >=", c.cfg.IdlePrevention.Interval)
-	task := func() {
-		if schedule.CheckWorkTime(c.cfg, time.Now()) {
-			logger.LogInfo("StartDaemon: idle threshold met, starting prevention")
+	logger.LogInfo("StartDaemon: will wait for idle >=", c.currentInterval())
+	logger.LogInfo("StartDaemon: active backends:", c.backend.Active())
+	c.mu.Lock()
+	c.running = true
+	stop := c.healthStop
+	c.mu.Unlock()
+	c.scheduler.ScheduleTask(c.runTask)
+	// 啟動健康檢查；停止通道以參數傳入，重新啟動換新通道時舊的迴圈仍能結束
+	go c.healthCheckLoop(stop)
+}
+
+// runTask 執行 preventIdle；若發生 panic 則先釋放電源鎖，讓下一次排程重新取得
+func (c *Controller) runTask() {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.LogError("Scheduled task panic, releasing power assertion:", r)
+			c.holdAssertion(false, "scheduled task panic")
+		}
+	}()
+	c.preventIdle()
+}
 
-			idle, err := preventidle.GetIdleTime()
+// preventIdle 為每次排程觸發的工作：工作時間內閒置超過門檻即模擬輸入；
+// assert 模式下則改為在工作時段內持有電源鎖。
+// 送出的事件數達到 rateLimit 上限時略過（或中止）模擬，改為持有電源鎖，直到限制解除或離開工作時段。
+func (c *Controller) preventIdle() {
+	now := c.now()
+	c.refreshAutoInterval(now)
+	if c.cfg.IdlePrevention.Mode == preventidle.ModeAssert {
+		if c.scheduler.CheckWorkTime(now) {
+			c.holdAssertion(true, "entered work window")
+		} else {
+			c.holdAssertion(false, "left work window")
+		}
+		return
+	}
+	if c.scheduler.CheckWorkTime(now) {
+		logger.LogInfo("StartDaemon: idle threshold met, starting prevention")
+
+		idle, err := c.activity.IdleTime()
+		if err != nil {
+			logger.LogError("WaitForIdle:", err)
+			return
+		}
+		userIdle, _ := c.activity.UserIdleTime()
+		logger.LogInfo(fmt.Sprintf("WaitForIdle: idle=%v/%v user idle=%v", idle, c.threshold, userIdle))
+
+		if idle >= c.threshold {
+			if err := c.limiter.Check(); err != nil {
+				logger.LogError("Skipping simulation, falling back to power assertion:", err)
+				c.holdAssertion(true, err.Error())
+				return
+			}
+			c.holdAssertion(false, "simulation rate limit lifted")
+			c.setDryRunReason(fmt.Sprintf("idle %v >= threshold %v", idle, c.threshold))
+			simulate := preventidle.SimulateActivity
+			if c.focus != nil {
+				simulate = c.focus.Simulate
+			}
+			err := simulate(c.activity, &c.cfg.IdlePrevention, c.rng)
+			if errors.Is(err, preventidle.ErrFocusDenied) {
+				logger.LogInfo("Focus:", err)
+				return
+			}
+			if errors.Is(err, preventidle.ErrRateLimited) {
+				// 一輪模擬中途用完配額：已送出的事件保留，其餘改由電源鎖接手
+				logger.LogError("Simulation stopped by rate limit, falling back to power assertion:", err)
+				c.holdAssertion(true, err.Error())
+				return
+			}
 			if err != nil {
-				logger.LogError("WaitForIdle:", err)
+				logger.LogError("Scheduled SimulateActivity error:", err)
 				return
 			}
-			logger.LogInfo("WaitForIdle: idle=%v/%v", idle, c.cfg.IdlePrevention.Interval)
+			c.threshold = c.nextThreshold()
+		}
+	} else {
+		logger.LogInfo(fmt.Sprintf("It's not working time now: %s", strings.ToLower(now.Weekday().String())))
+		// 釋放因達到頻率上限而改持有的電源鎖
+		c.holdAssertion(false, "left work window")
+		// 非工作時間不注入輸入，記錄的是真正的使用者閒置時間
+		userIdle, err := c.activity.UserIdleTime()
+		if err != nil {
+			logger.LogError("WaitForIdle:", err)
+			return
+		}
+		logger.LogInfo(fmt.Sprintf("WaitForIdle: user idle=%v", userIdle))
+	}
+}
 
-			if idle >= c.cfg.IdlePrevention.Interval {
-				err := preventidle.SimulateActivity(c.cfg.IdlePrevention.Mode)
-				if err != nil {
-					logger.LogError("Scheduled SimulateActivity error:", err)
-					return
-				}
-			}
-		} else {
-			logger.LogInfo("It's not working time now: %s", strings.ToLower(time.Now().Weekday().String()))
-			idle, _ := preventidle.GetIdleTime()
-			logger.LogInfo("WaitForIdle: idle=%v/%v", idle, c.cfg.IdlePrevention.Interval)
+// holdAssertion 取得或釋放電源鎖：assert 模式用於工作時段，其他模式用於達到頻率上限時的備援。
+// reason 會記錄在日誌與 dry-run 紀錄中。
+func (c *Controller) holdAssertion(hold bool, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hold == c.asserted {
+		return
+	}
+	if c.backend.Power == nil {
+		logger.LogError("Power assertion: no power assertion backend available")
+		return
+	}
+
+	c.setDryRunReason(reason)
+	if hold {
+		if err := c.backend.Power.PreventSleep(); err != nil {
+			logger.LogError("Power assertion: PreventSleep failed:", err)
+			return
 		}
+		logger.LogInfo(fmt.Sprintf("Power assertion held (%s)", reason))
+	} else {
+		if err := c.backend.Power.AllowIdle(); err != nil {
+			logger.LogError("Power assertion: AllowIdle failed:", err)
+			return
+		}
+		logger.LogInfo(fmt.Sprintf("Power assertion released (%s)", reason))
 	}
+	c.asserted = hold
+}
 
-	c.scheduler.ScheduleTask(task)
-	// 啟動健康檢查
-	go c.healthCheckLoop()
+// setDryRunReason 在 dry-run 模式下設定接下來記錄的動作原因
+func (c *Controller) setDryRunReason(reason string) {
+	if c.dryRun != nil {
+		c.dryRun.SetReason(reason)
+	}
 }
 
 func (c *Controller) StopDaemon() {
 	logger.LogInfo("Stopping daemon...")
+	c.mu.Lock()
+	c.running = false
+	stop := c.healthStop
+	c.mu.Unlock()
 	// 停健康檢查
-	close(c.healthStop)
+	close(stop)
 	// 停排程與持續輸入模擬
 	c.scheduler.StopScheduler()
-	// c.idleCtl.StopIdlePrevention()
+	// 釋放 assert 模式或頻率上限備援持有的電源鎖
+	c.holdAssertion(false, "daemon stopped")
+	// 電源鎖的釋放也要記錄，最後才關閉 dry-run 日誌
+	if c.journal != "" {
+		if err := c.dryRun.Close(); err != nil {
+			logger.LogError("Close dry-run journal failed:", err)
+		}
+	}
 }
 
 func (c *Controller) RestartDaemon() {
@@ -69,32 +346,47 @@ func (c *Controller) RestartDaemon() {
 	c.StopDaemon()
 	// 確保資源釋放
 	time.Sleep(100 * time.Millisecond)
+	c.mu.Lock()
 	c.healthStop = make(chan struct{})
-	c.scheduler = schedule.InitialScheduler(c.cfg)
+	c.mu.Unlock()
+	c.scheduler = c.newScheduler()
+	if c.journal != "" {
+		f, err := openJournal(c.journal)
+		if err != nil {
+			logger.LogError("Dry run:", err)
+		} else {
+			c.dryRun.SetJournal(f)
+		}
+	}
 	c.StartDaemon()
 }
 
-func (c *Controller) healthCheckLoop() {
+func (c *Controller) healthCheckLoop(stop <-chan struct{}) {
 	ticker := time.NewTicker(c.cfg.Scheduler.Interval)
 	defer ticker.Stop()
 	for {
 		select {
-		case <-c.healthStop:
+		case <-stop:
 			logger.LogInfo("Health check stopped")
 			return
 		case <-ticker.C:
-			if schedule.CheckWorkTime(c.cfg, time.Now()) {
-				idleTime, err := preventidle.GetIdleTime()
+			// assert 模式不模擬輸入，閒置時間變長是預期行為
+			if c.cfg.IdlePrevention.Mode == preventidle.ModeAssert {
+				continue
+			}
+			if c.scheduler.CheckWorkTime(c.now()) {
+				idleTime, err := c.activity.IdleTime()
 				if err != nil {
 					logger.LogError("HealthCheck: failed to get idle time:", err)
 					continue
 				}
-				// 如果閒置時間過長（例如 10 分鐘以上），可能代表模擬失效，嘗試重啟
-				if idleTime > c.cfg.IdlePrevention.Interval+(5*time.Minute) {
+				userIdle, _ := c.activity.UserIdleTime()
+				// 系統閒置時間包含我們注入的輸入；若仍過長（例如 10 分鐘以上），可能代表模擬失效，嘗試重啟
+				if idleTime > c.currentInterval()+(5*time.Minute) {
 					logger.LogError("HealthCheck: idle time too long (", idleTime, "), restarting prevention")
 					c.RestartDaemon()
 				} else {
-					logger.LogInfo("HealthCheck: idle time healthy (", idleTime, ")")
+					logger.LogInfo(fmt.Sprintf("HealthCheck: idle time healthy (%v, user idle %v)", idleTime, userIdle))
 				}
 			}
 		}
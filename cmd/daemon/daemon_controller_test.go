@@ -1,10 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/preventidle"
 	"github.com/HanksJCTsai/goidleguard/internal/schedule"
 )
 
@@ -54,10 +60,15 @@ func TestDaemonController_StartAndStop(t *testing.T) {
 	}
 
 	// 建立真實的 Controller 實例
+	backend := preventidle.NewFakeBackend().Backend()
 	ctrl := &Controller{
 		cfg:        cfg,
 		scheduler:  schedule.InitialScheduler(cfg),
+		backend:    backend,
+		activity:   preventidle.NewActivityTracker(backend.Injector, backend.Idle),
+		limiter:    preventidle.NewRateLimiter(cfg.IdlePrevention.RateLimit),
 		healthStop: make(chan struct{}),
+		now:        time.Now,
 	}
 
 	// 啟動 Daemon
@@ -73,3 +84,378 @@ func TestDaemonController_StartAndStop(t *testing.T) {
 
 	// 如果沒有 panic、沒有錯誤，代表啟動與停止都正常
 }
+
+// newTestConfig 建立週一 08:00–17:00 為工作時間的設定
+func newTestConfig(mode string) *config.APPConfig {
+	return &config.APPConfig{
+		Scheduler: config.SchedulerConfig{Interval: 5 * time.Minute},
+		IdlePrevention: config.IdlePreventionConfig{
+			Enabled:  true,
+			Interval: 10 * time.Minute,
+			Mode:     mode,
+		},
+		WorkSchedule: config.WorkSchedule{
+			"monday": {{Start: "08:00", End: "17:00"}},
+		},
+	}
+}
+
+func TestPreventIdle_SimulatesWhenIdleInWorkTime(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(newTestConfig("mixed"), fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(11 * time.Minute)
+	ctrl.preventIdle()
+
+	inputs := fake.Inputs()
+	if len(inputs) != 2 || inputs[0] != "key" || inputs[1] != "mouse" {
+		t.Errorf("Expected [key mouse], got %v", inputs)
+	}
+}
+
+func TestPreventIdle_SkipsWhenUserActive(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(newTestConfig("key"), fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(30 * time.Second)
+	ctrl.preventIdle()
+
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no simulated input, got %v", inputs)
+	}
+}
+
+func TestPreventIdle_SkipsOutsideWorkTime(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(newTestConfig("key"), fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 20, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(time.Hour)
+	ctrl.preventIdle()
+
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no simulated input outside work time, got %v", inputs)
+	}
+}
+
+func TestPreventIdle_TracksInjectedInput(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(newTestConfig("key"), fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(11 * time.Minute)
+	ctrl.preventIdle()
+
+	if ctrl.activity.Injections() != 1 {
+		t.Fatalf("Expected one tracked injection, got %d", ctrl.activity.Injections())
+	}
+	if idle, _ := ctrl.activity.UserIdleTime(); idle < 11*time.Minute {
+		t.Errorf("Expected user idle to ignore injected input, got %v", idle)
+	}
+}
+
+func TestNewController_RejectsFakeBackend(t *testing.T) {
+	// fake 後端只在 preventidle 的測試中註冊，正式設定不能選用
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.Backend = config.BackendList{preventidle.FakeBackendName}
+	if _, err := NewController(cfg); err == nil {
+		t.Error("Expected backend [fake] to be rejected, got nil")
+	}
+}
+
+func TestStatus_ReportsActiveBackend(t *testing.T) {
+	ctrl := NewControllerWithBackend(newTestConfig("key"), preventidle.NewFakeBackend().Backend())
+
+	status := ctrl.Status()
+	if status.Running {
+		t.Error("Expected daemon not running before StartDaemon")
+	}
+	if status.Backends.Input != preventidle.FakeBackendName || status.Backends.Power != preventidle.FakeBackendName {
+		t.Errorf("Expected fake backend in status, got %+v", status.Backends)
+	}
+
+	ctrl.StartDaemon()
+	if !ctrl.Status().Running {
+		t.Error("Expected daemon running after StartDaemon")
+	}
+	ctrl.StopDaemon()
+}
+
+func TestPreventIdle_AssertModeHoldsPowerInWorkWindow(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(newTestConfig("assert"), fake.Backend())
+	fake.SetIdle(time.Hour)
+
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+	ctrl.preventIdle()
+	if !fake.Asserted() {
+		t.Error("Expected power assertion held inside work window")
+	}
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no synthetic input in assert mode, got %v", inputs)
+	}
+
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 18, 0, 0, 0, time.Local) }
+	ctrl.preventIdle()
+	if fake.Asserted() {
+		t.Error("Expected power assertion released outside work window")
+	}
+}
+
+func TestStopDaemon_ReleasesAssertion(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(newTestConfig("assert"), fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	ctrl.StartDaemon()
+	ctrl.preventIdle()
+	if !fake.Asserted() {
+		t.Fatal("Expected power assertion held after preventIdle")
+	}
+	ctrl.StopDaemon()
+	if fake.Asserted() {
+		t.Error("Expected StopDaemon to release power assertion")
+	}
+}
+
+func TestPreventIdle_JitteredThreshold(t *testing.T) {
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.Jitter = config.JitterConfig{IntervalPercent: 50, Seed: 3}
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(cfg, fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 10; i++ {
+		threshold := ctrl.threshold
+		if threshold < 5*time.Minute || threshold > 15*time.Minute {
+			t.Fatalf("Expected threshold within ±50%% of 10m, got %v", threshold)
+		}
+		seen[threshold] = true
+		fake.SetIdle(threshold)
+		ctrl.preventIdle()
+	}
+	if len(fake.Inputs()) != 10 {
+		t.Errorf("Expected a simulation each time idle reached the threshold, got %d", len(fake.Inputs()))
+	}
+	if len(seen) < 2 {
+		t.Error("Expected the threshold to be resampled after each simulation")
+	}
+}
+
+func TestPreventIdle_DryRunJournalsInsteadOfInjecting(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.DryRun = true
+	var journal bytes.Buffer
+	ctrl := NewControllerWithBackend(cfg, preventidle.NewDryRun(&journal, fake.Backend(), &cfg.IdlePrevention).Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(11 * time.Minute)
+	ctrl.preventIdle()
+	// 已記錄過一次動作，dry-run 的閒置時間從該刻重新起算，不會每個 tick 都觸發
+	ctrl.preventIdle()
+
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no real input in dry run, got %v", inputs)
+	}
+	var entry preventidle.JournalEntry
+	lines := strings.Split(strings.TrimSpace(journal.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one journal entry, got %q", journal.String())
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Invalid journal line %q: %v", lines[0], err)
+	}
+	if entry.Mode != "key" || entry.Backend != preventidle.FakeBackendName ||
+		entry.Action != "key press (Shift)" || !strings.Contains(entry.Reason, "threshold") || entry.Time.IsZero() {
+		t.Errorf("Unexpected journal entry %+v", entry)
+	}
+}
+
+func TestNewController_DryRunUsesIdleSourceOnly(t *testing.T) {
+	// dry-run 只建立閒置來源；電源後端只推斷名稱，不應被建立
+	fake := preventidle.NewFakeBackend()
+	preventidle.RegisterIdleSource("dry-run-idle", func(*config.IdlePreventionConfig) (*preventidle.Backend, error) {
+		return &preventidle.Backend{Idle: fake}, nil
+	})
+	created := false
+	preventidle.RegisterBackend("dry-run-power", func(*config.IdlePreventionConfig) (*preventidle.Backend, error) {
+		created = true
+		return &preventidle.Backend{Power: fake}, nil
+	})
+	preventidle.RegisterCapabilities("dry-run-power", func(*config.IdlePreventionConfig) preventidle.Capabilities {
+		return preventidle.Capabilities{Power: true}
+	})
+	cfg := newTestConfig("assert")
+	cfg.IdlePrevention.Backend = config.BackendList{"dry-run-idle", "dry-run-power"}
+	cfg.IdlePrevention.DryRun = true
+	cfg.IdlePrevention.DryRunJournal = filepath.Join(t.TempDir(), "journal.jsonl")
+
+	ctrl, err := NewController(cfg)
+	if err != nil {
+		t.Fatalf("NewController failed: %v", err)
+	}
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+	ctrl.StartDaemon()
+	ctrl.preventIdle()
+	// 重新啟動會關閉再重新開啟日誌
+	ctrl.RestartDaemon()
+	ctrl.preventIdle()
+	ctrl.StopDaemon()
+	if err := ctrl.dryRun.PreventSleep(); err == nil {
+		t.Error("Expected journal closed after StopDaemon")
+	}
+
+	data, err := os.ReadFile(cfg.IdlePrevention.DryRunJournal)
+	if err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	if n := strings.Count(string(data), `"action":"prevent-sleep"`); n != 2 {
+		t.Errorf("Expected a power assertion journaled before and after restart, got %d in %q", n, data)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry preventidle.JournalEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid journal line %q: %v", line, err)
+		}
+		if entry.Backend != "dry-run-power" {
+			t.Errorf("Expected power entries journaled with backend dry-run-power, got %+v", entry)
+		}
+	}
+	if created {
+		t.Error("Expected dry-run not to create the power backend")
+	}
+}
+
+func TestPreventIdle_DryRunAssertMode(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	cfg := newTestConfig("assert")
+	var journal bytes.Buffer
+	ctrl := NewControllerWithBackend(cfg, preventidle.NewDryRun(&journal, fake.Backend(), &cfg.IdlePrevention).Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	ctrl.preventIdle()
+	ctrl.holdAssertion(false, "left work window")
+
+	if fake.Asserted() {
+		t.Error("Expected no real power assertion in dry run")
+	}
+	journaled := journal.String()
+	if !strings.Contains(journaled, `"action":"prevent-sleep","reason":"entered work window"`) ||
+		!strings.Contains(journaled, `"action":"allow-idle","reason":"left work window"`) {
+		t.Errorf("Expected power assertion entries in journal, got %q", journaled)
+	}
+}
+
+func TestPreventIdle_RateLimitFallsBackToAssertion(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.RateLimit = config.RateLimitConfig{PerHour: 1}
+	ctrl := NewControllerWithBackend(cfg, fake.Backend())
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(11 * time.Minute)
+	ctrl.preventIdle()
+	fake.SetIdle(11 * time.Minute)
+	ctrl.preventIdle()
+
+	if inputs := fake.Inputs(); len(inputs) != 1 {
+		t.Errorf("Expected a single simulation within the hourly quota, got %v", inputs)
+	}
+	if !fake.Asserted() {
+		t.Error("Expected power assertion held once the limit is reached")
+	}
+
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 18, 0, 0, 0, time.Local) }
+	ctrl.preventIdle()
+	if fake.Asserted() {
+		t.Error("Expected fallback assertion released outside work window")
+	}
+}
+
+// focusedTerminal 為固定回報終端機取得焦點的焦點來源
+type focusedTerminal struct{}
+
+func (focusedTerminal) FocusedWindow() (preventidle.FocusedWindow, error) {
+	return preventidle.FocusedWindow{Instance: "xterm", Class: "XTerm"}, nil
+}
+
+func TestPreventIdle_FocusDeniedSkipsSimulation(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.Focus = config.FocusConfig{Deny: []string{"xterm"}}
+	ctrl := NewControllerWithBackend(cfg, fake.Backend())
+	ctrl.focus = preventidle.NewFocusGuard(focusedTerminal{}, cfg.IdlePrevention.Focus)
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	fake.SetIdle(11 * time.Minute)
+	ctrl.preventIdle()
+
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no input while a denied window is focused, got %v", inputs)
+	}
+}
+
+func TestPreventIdle_FocusDeniedDoesNotConsumeRateLimit(t *testing.T) {
+	fake := preventidle.NewFakeBackend()
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.RateLimit = config.RateLimitConfig{PerHour: 1}
+	cfg.IdlePrevention.Focus = config.FocusConfig{Deny: []string{"xterm"}}
+	ctrl := NewControllerWithBackend(cfg, fake.Backend())
+	ctrl.focus = preventidle.NewFocusGuard(focusedTerminal{}, cfg.IdlePrevention.Focus)
+	ctrl.now = func() time.Time { return time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local) }
+
+	for i := 0; i < 3; i++ {
+		fake.SetIdle(11 * time.Minute)
+		ctrl.preventIdle()
+	}
+	if err := ctrl.limiter.Check(); err != nil {
+		t.Errorf("Expected denied rounds not to consume the quota, got %v", err)
+	}
+	if fake.Asserted() {
+		t.Error("Expected no fallback assertion while quota remains")
+	}
+}
+
+func TestPreventIdle_AutoIntervalRechecksTimeout(t *testing.T) {
+	cfg := newTestConfig("key")
+	cfg.IdlePrevention.Interval = 0
+	cfg.IdlePrevention.IntervalAuto = true
+	cfg.IdlePrevention.AutoInterval.Recheck = 10 * time.Minute
+	fake := preventidle.NewFakeBackend()
+	ctrl := NewControllerWithBackend(cfg, fake.Backend())
+
+	timeout := 40 * time.Minute
+	ctrl.timeoutProbes = []preventidle.TimeoutProbe{{Name: "test", Read: func() (time.Duration, error) { return timeout, nil }}}
+	now := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local)
+	ctrl.now = func() time.Time { return now }
+	ctrl.autoRecheck = time.Time{}
+
+	fake.SetIdle(15 * time.Minute)
+	ctrl.preventIdle()
+	if got := ctrl.currentInterval(); got != 20*time.Minute {
+		t.Fatalf("Expected interval 20m from a 40m timeout, got %v", got)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no input below the auto threshold, got %v", inputs)
+	}
+
+	// 逾時改變後，要到下次重新檢查時才生效
+	timeout = 24 * time.Minute
+	now = now.Add(5 * time.Minute)
+	ctrl.preventIdle()
+	if got := ctrl.currentInterval(); got != 20*time.Minute {
+		t.Errorf("Expected interval unchanged before recheck, got %v", got)
+	}
+	now = now.Add(5 * time.Minute)
+	ctrl.preventIdle()
+	if got := ctrl.currentInterval(); got != 12*time.Minute {
+		t.Errorf("Expected interval 12m after recheck, got %v", got)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 1 {
+		t.Errorf("Expected simulation once idle exceeds the new threshold, got %v", inputs)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,16 +11,37 @@ import (
 )
 
 func main() {
+	// record 子指令：錄製真實活動節奏供 replay 模式使用
+	if len(os.Args) > 1 && os.Args[1] == "record" {
+		logger.InitLogger()
+		if err := runRecord(os.Args[2:]); err != nil {
+			logger.LogError("Record failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	configPath := flag.String("config", "config.yaml", "path to the configuration file")
+	dryRun := flag.Bool("dry-run", false, "journal intended actions instead of injecting input (overrides idlePrevention.dryRun)")
+	flag.Parse()
+
 	logger.InitLogger()
-	cfg, err := config.LoadConfig("config.yaml")
+	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		logger.LogError("Failed to load config:", err)
 		os.Exit(1)
 	}
 	logger.LogInfo("Config loaded successfully")
+	if *dryRun {
+		cfg.IdlePrevention.DryRun = true
+	}
 
 	// 建立並啟動 DaemonController
-	dc := NewController(cfg)
+	dc, err := NewController(cfg)
+	if err != nil {
+		logger.LogError("Failed to initialize idle prevention backend:", err)
+		os.Exit(1)
+	}
 	dc.StartDaemon()
 
 	// 捕捉系統中斷訊號以優雅關閉
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/preventidle"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// runRecord 實作 record 子指令：在取樣期間讀取閒置來源，把真實活動的間隔分布與鍵盤／滑鼠比例
+// 寫成 replay 模式使用的節奏檔。只記錄事件類型與時間分布，不記錄任何按鍵內容。
+func runRecord(args []string) error {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to the configuration file")
+	duration := fs.Duration("duration", 30*time.Minute, "how long to record")
+	poll := fs.Duration("poll", 250*time.Millisecond, "idle time sampling interval")
+	out := fs.String("o", "profile.json", "activity profile file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *duration <= 0 || *poll <= 0 {
+		return fmt.Errorf("duration and poll must be > 0")
+	}
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.ParseYAMLConfig(data)
+	if err != nil {
+		return err
+	}
+	// 錄製正是為了產生 replay 模式的節奏檔，驗證時不要求節奏檔已經存在，其餘設定照常驗證
+	check := *cfg
+	if check.IdlePrevention.Mode == preventidle.ModeReplay {
+		check.IdlePrevention.Mode = preventidle.ModeAssert
+	}
+	if err := config.ValidateConfig(&check); err != nil {
+		return err
+	}
+	// 錄製只讀取閒置時間，不建立輸入裝置或電源鎖
+	chain, err := preventidle.SelectIdleBackends(&cfg.IdlePrevention)
+	if err != nil {
+		return err
+	}
+	source := chain.Active().Idle
+	if source == "" {
+		return fmt.Errorf("no backend provides idle time")
+	}
+
+	stop := make(chan struct{})
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		<-sigCh
+		close(stop)
+	}()
+
+	logger.LogInfo(fmt.Sprintf("Recording activity from %s for %v (Ctrl+C to stop early)", source, *duration))
+	p, err := preventidle.RecordProfile(chain, source, *duration, *poll, stop)
+	if err != nil {
+		return err
+	}
+	if err := preventidle.SaveProfile(*out, p); err != nil {
+		return err
+	}
+	logger.LogInfo(fmt.Sprintf("Recorded %d events (%d key, %d mouse, %d unknown) to %s",
+		p.Events, p.Kinds[preventidle.ActionKey], p.Kinds[preventidle.ActionMouse], p.Kinds[preventidle.ProfileKindUnknown], *out))
+	return nil
+}
@@ -3,5 +3,7 @@ module github.com/HanksJCTsai/goidleguard
 go 1.24.1
 
 require (
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/jezek/xgb v1.1.1
+	gopkg.in/yaml.v3 v3.0.1
 )
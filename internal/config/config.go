@@ -3,7 +3,15 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
+	"runtime"
 	"time"
+	_ "time/tzdata" // 內嵌時區資料庫，沒有系統 zoneinfo 的機器（例如 Windows）也能載入 IANA 時區
+
+	"github.com/HanksJCTsai/goidleguard/internal/cron"
+	"github.com/HanksJCTsai/goidleguard/internal/ical"
+	"github.com/HanksJCTsai/goidleguard/internal/keys"
+	"github.com/HanksJCTsai/goidleguard/internal/macro"
 )
 
 // LoadConfig 讀取指定檔案（例如 config.yaml），並反序列化成 Config 結構。
@@ -49,21 +57,133 @@ func ValidateConfig(cfg *APPConfig) error {
 		return fmt.Errorf("invalid Scheduler.interval must be >0 (%s)", cfg.Scheduler.Interval)
 	}
 
-	// 驗證 IdlePrevention 的 Interval 格式
-	if cfg.IdlePrevention.Interval <= 0 {
-		return fmt.Errorf("invalid idlePrevention.interval must be >0 (%s)", cfg.IdlePrevention.Interval)
+	// 驗證 IdlePrevention 的 Interval 格式；auto 時由常駐程式在執行期推算
+	if cfg.IdlePrevention.IntervalAuto {
+		auto := cfg.IdlePrevention.AutoInterval
+		if auto.Fraction < 0 || auto.Fraction >= 1 {
+			return fmt.Errorf("invalid idlePrevention.autoInterval.fraction must be in [0, 1) (%v)", auto.Fraction)
+		}
+		if auto.Recheck < 0 || auto.Fallback < 0 {
+			return fmt.Errorf("invalid idlePrevention.autoInterval: recheck and fallback must be >= 0")
+		}
+		if auto.Fallback > 0 && cfg.Scheduler.Interval >= auto.Fallback {
+			return fmt.Errorf("IdlePrevention.tick (%v) must be <= idlePrevention.autoInterval.fallback (%v)",
+				cfg.Scheduler.Interval, auto.Fallback)
+		}
+	} else {
+		if cfg.IdlePrevention.Interval <= 0 {
+			return fmt.Errorf("invalid idlePrevention.interval must be >0 (%s)", cfg.IdlePrevention.Interval)
+		}
+
+		if cfg.Scheduler.Interval >= cfg.IdlePrevention.Interval {
+			return fmt.Errorf("IdlePrevention.tick (%v) must be <= IdlePrevention.interval (%v)",
+				cfg.Scheduler.Interval, cfg.IdlePrevention.Interval)
+		}
+	}
+
+	// 驗證 IdlePrevention 的 Mode 是否已註冊，並執行該模式的設定檢查
+	if err := ValidateMode(&cfg.IdlePrevention); err != nil {
+		return err
+	}
+
+	// 驗證 IdlePrevention 的 Backend 清單："auto" 只能單獨使用，名稱不可為空或重複
+	seen := map[string]bool{}
+	for _, name := range cfg.IdlePrevention.Backend {
+		if name == "" {
+			return fmt.Errorf("invalid idlePrevention.backend: empty backend name")
+		}
+		if name == "auto" && len(cfg.IdlePrevention.Backend) > 1 {
+			return fmt.Errorf("invalid idlePrevention.backend: \"auto\" cannot be combined with other backends")
+		}
+		if seen[name] {
+			return fmt.Errorf("invalid idlePrevention.backend: duplicate backend %q", name)
+		}
+		seen[name] = true
 	}
 
-	if cfg.Scheduler.Interval >= cfg.IdlePrevention.Interval {
-		return fmt.Errorf("IdlePrevention.tick (%v) must be <= IdlePrevention.interval (%v)",
-			cfg.Scheduler.Interval, cfg.IdlePrevention.Interval)
+	// 驗證自訂動作的類型與必要欄位；按鍵名稱依本平台的按鍵對照表檢查
+	for i, a := range cfg.IdlePrevention.Actions {
+		switch a.Type {
+		case "key":
+			if a.Key == "" {
+				return fmt.Errorf("invalid idlePrevention.actions[%d]: key action requires a key name", i)
+			}
+			if _, err := keys.LookupFor(a.Key, runtime.GOOS); err != nil {
+				return fmt.Errorf("invalid idlePrevention.actions[%d]: %w", i, err)
+			}
+		case "mouse":
+			if a.Pattern == "" && a.DX == 0 && a.DY == 0 {
+				return fmt.Errorf("invalid idlePrevention.actions[%d]: mouse action requires a non-zero dx or dy, or a pattern", i)
+			}
+			if a.Radius < 0 || a.Steps < 0 || a.StepDelay < 0 {
+				return fmt.Errorf("invalid idlePrevention.actions[%d]: radius, steps and stepDelay must be >= 0", i)
+			}
+		case "click":
+			if a.Button != "" && a.Button != "left" && a.Button != "right" && a.Button != "middle" {
+				return fmt.Errorf("invalid idlePrevention.actions[%d]: unknown mouse button %q", i, a.Button)
+			}
+		default:
+			return fmt.Errorf("invalid idlePrevention.actions[%d]: unknown action type %q; must be one of: key, mouse, click", i, a.Type)
+		}
 	}
 
-	// 驗證 IdlePrevention 的 Mode 值是否正確
-	if cfg.IdlePrevention.Mode != "key" &&
-		cfg.IdlePrevention.Mode != "mouse" &&
-		cfg.IdlePrevention.Mode != "mixed" {
-		return errInvalidMode
+	// 驗證巨集語法與 press 的按鍵名稱，錯誤訊息包含行號與欄位
+	for name, src := range cfg.IdlePrevention.Macros {
+		m, err := macro.Parse(name, src)
+		if err != nil {
+			return fmt.Errorf("invalid idlePrevention.macros: %w", err)
+		}
+		for _, st := range m.Statements {
+			if st.Op != macro.OpPress {
+				continue
+			}
+			if _, err := keys.LookupFor(st.Key, runtime.GOOS); err != nil {
+				return fmt.Errorf("invalid idlePrevention.macros: %w", &macro.SyntaxError{Macro: name, Pos: st.Pos, Msg: err.Error()})
+			}
+		}
+	}
+	if name := cfg.IdlePrevention.Macro; name != "" {
+		if _, ok := cfg.IdlePrevention.Macros[name]; !ok {
+			return fmt.Errorf("invalid idlePrevention.macro: no macro named %q", name)
+		}
+	}
+
+	// 驗證 Jitter 的幅度、權重與延遲範圍
+	jitter := cfg.IdlePrevention.Jitter
+	if jitter.IntervalPercent < 0 || jitter.IntervalPercent >= 100 {
+		return fmt.Errorf("invalid idlePrevention.jitter.intervalPercent must be in [0, 100) (%v)", jitter.IntervalPercent)
+	}
+	for name, w := range jitter.Weights {
+		if name != "key" && name != "mouse" && name != "click" {
+			return fmt.Errorf("invalid idlePrevention.jitter.weights: unknown action type %q", name)
+		}
+		if w < 0 {
+			return fmt.Errorf("invalid idlePrevention.jitter.weights.%s must be >= 0 (%v)", name, w)
+		}
+	}
+	if jitter.MinDelay < 0 || jitter.MaxDelay < 0 || (jitter.MaxDelay > 0 && jitter.MaxDelay < jitter.MinDelay) {
+		return fmt.Errorf("invalid idlePrevention.jitter delay range (%v–%v)", jitter.MinDelay, jitter.MaxDelay)
+	}
+
+	// 驗證模擬頻率上限
+	if rl := cfg.IdlePrevention.RateLimit; rl.PerMinute < 0 || rl.PerHour < 0 || rl.PerDay < 0 {
+		return fmt.Errorf("invalid idlePrevention.rateLimit: limits must be >= 0 (%d/minute, %d/hour, %d/day)", rl.PerMinute, rl.PerHour, rl.PerDay)
+	}
+
+	// 驗證 replay 的範圍；節奏檔本身由 replay 模式的驗證函式檢查
+	if r := cfg.IdlePrevention.Replay; r.MaxGap < 0 || r.MaxEvents < 0 {
+		return fmt.Errorf("invalid idlePrevention.replay: maxGap and maxEvents must be >= 0")
+	}
+
+	// 驗證焦點視窗規則
+	focus := cfg.IdlePrevention.Focus
+	if focus.OnDeny != "" && focus.OnDeny != "skip" && focus.OnDeny != "mouse" {
+		return fmt.Errorf("invalid idlePrevention.focus.onDeny %q; must be one of: skip, mouse", focus.OnDeny)
+	}
+	for _, pattern := range append(append([]string(nil), focus.Allow...), focus.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid idlePrevention.focus pattern %q: %w", pattern, err)
+		}
 	}
 
 	// 驗證 RetryPolicy 的 RetryInterval 格式
@@ -71,27 +191,52 @@ func ValidateConfig(cfg *APPConfig) error {
 		return fmt.Errorf("invalid retryPolicy.retryInterval format (%s): %w", cfg.RetryPolicy.RetryInterval, err)
 	}
 
-	// 驗證 WorkSchedule 每日的工作時段
+	// 驗證假日行事曆可以讀取並解析
+	for _, file := range cfg.Holidays.Calendars {
+		if _, err := ical.ParseFile(file); err != nil {
+			return fmt.Errorf("invalid holidays.calendars entry: %w", err)
+		}
+	}
+
+	// 驗證 WorkSchedule 的時區與每日的工作時段
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			return fmt.Errorf("invalid workSchedule.timezone (%s): %w", cfg.Timezone, err)
+		}
+	}
 	for day, sessions := range cfg.WorkSchedule {
-		for _, session := range sessions {
-			start, err := time.Parse("15:04", session.Start)
-			if err != nil {
-				return fmt.Errorf("invalid workSchedule.%s start time (%s): %w", day, session.Start, err)
-			}
-			end, err := time.Parse("15:04", session.End)
-			if err != nil {
-				return fmt.Errorf("invalid workSchedule.%s end time (%s): %w", day, session.End, err)
-			}
-			if !start.Before(end) {
-				return fmt.Errorf("in workSchedule for %s, start time (%s) must be before end time (%s)", day, session.Start, session.End)
-			}
+		if err := validateSessions("workSchedule."+day, sessions); err != nil {
+			return err
 		}
 	}
 
+	// 驗證特定日期的設定
+	if err := ValidateOverrides(cfg.Overrides); err != nil {
+		return err
+	}
+
+	// 解析 cron 工作時窗並保留結果，排程判斷時不再重新解析
+	windows, err := ParseCronWindows(cfg.CronWindows)
+	if err != nil {
+		return err
+	}
+	cfg.CronSchedule = windows
+
 	return nil
 }
 
-var errInvalidMode = &InvalidModeError{"Invalid idle prevention mode; must be one of: key, mouse, mixed"}
+// ParseCronWindows 解析 cronWindows 的每一項，任一項無效即回傳錯誤
+func ParseCronWindows(entries []string) ([]*cron.Window, error) {
+	windows := make([]*cron.Window, 0, len(entries))
+	for _, entry := range entries {
+		w, err := cron.ParseWindow(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cronWindows entry: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
 
 func (e *InvalidModeError) Error() string {
 	return e.Message
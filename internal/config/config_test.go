@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -14,7 +17,7 @@ version:
   version: "1.0.0"
 
 scheduler:
-  interval: "10m"
+  interval: "1m"
 
 idlePrevention:
   enabled: true
@@ -153,7 +156,7 @@ func TestSaveConfig(t *testing.T) {
 }
 
 func TestValidateConfig_InvalidWorkSchedule(t *testing.T) {
-	// 測試當某天工作時段中，開始時間不小於結束時間的情形
+	// 測試當某天工作時段中，開始時間等於結束時間的情形
 	cfg := &APPConfig{
 		Version: VersionConfig{
 			Name:    "TestApp",
@@ -177,7 +180,7 @@ func TestValidateConfig_InvalidWorkSchedule(t *testing.T) {
 		},
 		WorkSchedule: WorkSchedule{
 			"monday": {
-				{Start: "08:00", End: "07:00"}, // 開始時間晚於結束時間
+				{Start: "08:00", End: "08:00"}, // 開始時間等於結束時間
 			},
 		},
 	}
@@ -186,6 +189,12 @@ func TestValidateConfig_InvalidWorkSchedule(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error for invalid monday workSchedule, got nil")
 	}
+
+	// 結束時間早於開始時間為跨越午夜的時段
+	cfg.WorkSchedule["monday"][0] = WorkSession{Start: "22:00", End: "06:00"}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected overnight session to be valid, got %v", err)
+	}
 }
 
 func TestValidateConfig_InvalidIdlePreventionMode(t *testing.T) {
@@ -221,7 +230,7 @@ func TestValidateConfig_InvalidIdlePreventionMode(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error for invalid IdlePrevention.Mode, got nil")
 	} else {
-		expected := "Invalid idle prevention mode; must be one of: key, mouse, mixed"
+		expected := "Invalid idle prevention mode; must be one of: key, mouse, mixed, assert"
 		if err.Error() != expected {
 			t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
 		}
@@ -382,3 +391,460 @@ func TestTimeParsingForWorkSchedule(t *testing.T) {
 		t.Errorf("Failed to parse end time: %v", err)
 	}
 }
+
+func TestParseYAMLConfig_Backend(t *testing.T) {
+	cfg, err := ParseYAMLConfig([]byte("idlePrevention:\n  backend: auto\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLConfig failed: %v", err)
+	}
+	if len(cfg.IdlePrevention.Backend) != 1 || cfg.IdlePrevention.Backend[0] != "auto" {
+		t.Errorf("Expected [auto], got %v", cfg.IdlePrevention.Backend)
+	}
+
+	cfg, err = ParseYAMLConfig([]byte("idlePrevention:\n  backend: [uinput, xtest, logind]\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLConfig failed: %v", err)
+	}
+	if len(cfg.IdlePrevention.Backend) != 3 || cfg.IdlePrevention.Backend[1] != "xtest" {
+		t.Errorf("Expected [uinput xtest logind], got %v", cfg.IdlePrevention.Backend)
+	}
+
+	cfg, err = ParseJSONConfig([]byte(`{"idlePrevention": {"backend": "auto"}}`))
+	if err != nil {
+		t.Fatalf("ParseJSONConfig failed: %v", err)
+	}
+	if len(cfg.IdlePrevention.Backend) != 1 || cfg.IdlePrevention.Backend[0] != "auto" {
+		t.Errorf("Expected [auto], got %v", cfg.IdlePrevention.Backend)
+	}
+}
+
+func TestValidateConfig_InvalidBackend(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "key",
+			Backend:  BackendList{"auto", "uinput"},
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for auto combined with other backends, got nil")
+	}
+
+	cfg.IdlePrevention.Backend = BackendList{"uinput", "uinput"}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for duplicate backend, got nil")
+	}
+
+	cfg.IdlePrevention.Backend = BackendList{"uinput", "logind"}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid backend list, got error: %v", err)
+	}
+}
+
+func TestValidateConfig_AssertMode(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "assert",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected assert mode to be valid, got error: %v", err)
+	}
+}
+
+func TestParseYAMLConfig_Actions(t *testing.T) {
+	data := []byte(`
+idlePrevention:
+  allowVisible: false
+  actions:
+    - type: key
+      key: F15
+    - type: mouse
+      dx: 1
+      dy: -1
+`)
+	cfg, err := ParseYAMLConfig(data)
+	if err != nil {
+		t.Fatalf("ParseYAMLConfig failed: %v", err)
+	}
+	actions := cfg.IdlePrevention.Actions
+	if len(actions) != 2 || actions[0].Key != "F15" || actions[1].DX != 1 || actions[1].DY != -1 {
+		t.Errorf("Unexpected actions: %+v", actions)
+	}
+}
+
+func TestValidateConfig_InvalidActions(t *testing.T) {
+	base := func(a ActionConfig) *APPConfig {
+		return &APPConfig{
+			Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+			IdlePrevention: IdlePreventionConfig{
+				Enabled:  true,
+				Interval: (5 * time.Minute),
+				Mode:     "key",
+				Actions:  []ActionConfig{a},
+			},
+			RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+		}
+	}
+	for _, a := range []ActionConfig{
+		{Type: "type"},
+		{Type: "key"},
+		{Type: "key", Key: "hyper"},
+		{Type: "mouse"},
+		{Type: "click", Button: "side"},
+	} {
+		if err := ValidateConfig(base(a)); err == nil {
+			t.Errorf("Expected error for action %+v, got nil", a)
+		}
+	}
+	if err := ValidateConfig(base(ActionConfig{Type: "click"})); err != nil {
+		t.Errorf("Expected click action valid, got error: %v", err)
+	}
+}
+
+func TestValidateConfig_InvalidJitter(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "mixed",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	for _, j := range []JitterConfig{
+		{IntervalPercent: 100},
+		{IntervalPercent: -1},
+		{Weights: map[string]float64{"scroll": 1}},
+		{Weights: map[string]float64{"key": -1}},
+		{MinDelay: time.Second, MaxDelay: time.Millisecond},
+	} {
+		cfg.IdlePrevention.Jitter = j
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("Expected error for jitter %+v, got nil", j)
+		}
+	}
+
+	cfg.IdlePrevention.Jitter = JitterConfig{
+		IntervalPercent: 20,
+		Weights:         map[string]float64{"key": 1, "mouse": 3},
+		MinDelay:        50 * time.Millisecond,
+		MaxDelay:        300 * time.Millisecond,
+		Seed:            42,
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid jitter, got error: %v", err)
+	}
+}
+
+func TestValidateConfig_Macros(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "key",
+			Macros:   map[string]string{"nudge": "press shift\nwait 50ms\nmove 3;"},
+			Macro:    "nudge",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	err := ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "line 3, column 6") {
+		t.Errorf("Expected macro syntax error at line 3, column 6, got %v", err)
+	}
+
+	cfg.IdlePrevention.Macros["nudge"] = "wait 50ms\n  press hyper"
+	err = ValidateConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "line 2, column 3") || !strings.Contains(err.Error(), "unknown key name") {
+		t.Errorf("Expected unknown key error at line 2, column 3, got %v", err)
+	}
+
+	cfg.IdlePrevention.Macros["nudge"] = "press shift; wait 50ms; move 3,0; wait 20ms; move -3,0; scroll 0"
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid macro, got error: %v", err)
+	}
+
+	cfg.IdlePrevention.Macro = "missing"
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for unknown macro name, got nil")
+	}
+}
+
+func TestValidateConfig_RegisteredMode(t *testing.T) {
+	RegisterMode("test-quiet", func(cfg *IdlePreventionConfig) error {
+		if !cfg.AllowVisible {
+			return errors.New("test-quiet requires allowVisible")
+		}
+		return nil
+	})
+	cfg := &APPConfig{
+		Scheduler:      SchedulerConfig{Interval: time.Minute},
+		IdlePrevention: IdlePreventionConfig{Interval: 5 * time.Minute, Mode: "test-quiet"},
+		RetryPolicy:    RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "requires allowVisible") {
+		t.Errorf("Expected mode validator error, got %v", err)
+	}
+	cfg.IdlePrevention.AllowVisible = true
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected registered mode accepted, got %v", err)
+	}
+	if names := ModeNames(); names[len(names)-1] != "test-quiet" {
+		t.Errorf("Expected test-quiet listed last, got %v", names)
+	}
+}
+
+func TestValidateConfig_InvalidRateLimit(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:   true,
+			Interval:  (5 * time.Minute),
+			Mode:      "mixed",
+			RateLimit: RateLimitConfig{PerMinute: 2, PerHour: -1},
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for negative rate limit, got nil")
+	}
+	cfg.IdlePrevention.RateLimit.PerHour = 30
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid rate limit, got %v", err)
+	}
+}
+
+func TestValidateConfig_InvalidFocus(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "mixed",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	for _, f := range []FocusConfig{
+		{Deny: []string{"[terminal"}},
+		{Deny: []string{"zoom"}, OnDeny: "type"},
+	} {
+		cfg.IdlePrevention.Focus = f
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("Expected error for focus %+v, got nil", f)
+		}
+	}
+	cfg.IdlePrevention.Focus = FocusConfig{Allow: []string{"code"}, Deny: []string{"*terminal*"}, OnDeny: "mouse"}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid focus rules, got %v", err)
+	}
+}
+
+func TestParseYAMLConfig_IntervalAuto(t *testing.T) {
+	cfg, err := ParseYAMLConfig([]byte(`
+scheduler:
+  interval: "1s"
+idlePrevention:
+  interval: auto
+  mode: "key"
+  autoInterval:
+    fraction: 0.6
+retryPolicy:
+  retryInterval: "10s"
+`))
+	if err != nil {
+		t.Fatalf("ParseYAMLConfig failed: %v", err)
+	}
+	if !cfg.IdlePrevention.IntervalAuto || cfg.IdlePrevention.Mode != "key" || cfg.IdlePrevention.AutoInterval.Fraction != 0.6 {
+		t.Fatalf("Unexpected idlePrevention %+v", cfg.IdlePrevention)
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected interval: auto to validate, got %v", err)
+	}
+
+	// 執行期推算的值不會寫回設定檔
+	cfg.IdlePrevention.Interval = 3 * time.Minute
+	data, err := MarshalYAML(cfg)
+	if err != nil {
+		t.Fatalf("MarshalYAML failed: %v", err)
+	}
+	if !strings.Contains(string(data), "interval: auto") {
+		t.Errorf("Expected interval: auto preserved, got:\n%s", data)
+	}
+
+	data, err = MarshalJSON(cfg)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	parsed, err := ParseJSONConfig(data)
+	if err != nil {
+		t.Fatalf("ParseJSONConfig failed: %v", err)
+	}
+	if !parsed.IdlePrevention.IntervalAuto || parsed.IdlePrevention.Mode != "key" {
+		t.Errorf("Expected interval auto preserved through JSON, got %+v", parsed.IdlePrevention)
+	}
+
+	cfg.IdlePrevention.AutoInterval.Fraction = 1.5
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for fraction >= 1, got nil")
+	}
+}
+
+func TestWorkScheduleTimezone(t *testing.T) {
+	data := []byte(`
+workSchedule:
+  timezone: Europe/Berlin
+  monday:
+    - start: "09:00"
+      end: "17:00"
+      timezone: UTC
+`)
+	cfg, err := ParseYAMLConfig(data)
+	if err != nil {
+		t.Fatalf("ParseYAMLConfig failed: %v", err)
+	}
+	if cfg.Timezone != "Europe/Berlin" || len(cfg.WorkSchedule) != 1 || cfg.WorkSchedule["monday"][0].Timezone != "UTC" {
+		t.Fatalf("Unexpected timezone %q schedule %v", cfg.Timezone, cfg.WorkSchedule)
+	}
+
+	out, err := MarshalYAML(cfg)
+	if err != nil {
+		t.Fatalf("MarshalYAML failed: %v", err)
+	}
+	if again, err := ParseYAMLConfig(out); err != nil || again.Timezone != "Europe/Berlin" {
+		t.Errorf("Expected timezone to survive YAML round trip, got %v (%v)", again, err)
+	}
+	js, err := MarshalJSON(cfg)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	again, err := ParseJSONConfig(js)
+	if err != nil || again.Timezone != "Europe/Berlin" || len(again.WorkSchedule["monday"]) != 1 {
+		t.Errorf("Expected timezone to survive JSON round trip, got %v (%v)", again, err)
+	}
+
+	cfg = &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "mixed",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+		Timezone:    "Mars/Olympus_Mons",
+	}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for unknown timezone, got nil")
+	}
+	cfg.Timezone = "Asia/Tokyo"
+	cfg.WorkSchedule = WorkSchedule{"monday": {{Start: "09:00", End: "17:00", Timezone: "Nowhere/Else"}}}
+	if err := ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for unknown session timezone, got nil")
+	}
+	cfg.WorkSchedule["monday"][0].Timezone = "America/Sao_Paulo"
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid timezones, got %v", err)
+	}
+}
+
+func TestValidateConfig_InvalidHolidays(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "holidays.ics")
+	bad := filepath.Join(dir, "broken.ics")
+	os.WriteFile(good, []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20251225\nEND:VEVENT\nEND:VCALENDAR\n"), 0644)
+	os.WriteFile(bad, []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20251225\n"), 0644)
+
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "mixed",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	for _, calendars := range [][]string{{bad}, {good, filepath.Join(dir, "missing.ics")}} {
+		cfg.Holidays.Calendars = calendars
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("Expected error for calendars %v, got nil", calendars)
+		}
+	}
+	cfg.Holidays.Calendars = []string{good}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid calendars, got %v", err)
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	cfg, err := ParseYAMLConfig([]byte(`
+overrides:
+  "2026-12-24":
+    sessions:
+      - start: "08:00"
+        end: "12:00"
+  "2026-11-02":
+    off: true
+  "2026-12-28/2026-12-30":
+    mode: extend
+    sessions:
+      - start: "20:00"
+        end: "02:00"
+`))
+	if err != nil {
+		t.Fatalf("ParseYAMLConfig failed: %v", err)
+	}
+	if err := ValidateOverrides(cfg.Overrides); err != nil {
+		t.Errorf("Expected valid overrides, got %v", err)
+	}
+	if o, ok := cfg.Overrides.Lookup(time.Date(2026, time.December, 29, 23, 0, 0, 0, time.Local)); !ok || o.Mode != OverrideExtend {
+		t.Errorf("Expected date range lookup to match, got %+v %v", o, ok)
+	}
+
+	session := []WorkSession{{Start: "09:00", End: "12:00"}}
+	for name, overrides := range map[string]ScheduleOverrides{
+		"bad date":          {"2026-13-01": {Off: true}},
+		"reversed range":    {"2026-12-31/2026-12-01": {Off: true}},
+		"off with sessions": {"2026-12-24": {Off: true, Sessions: session}},
+		"off and extend":    {"2026-12-24": {Off: true, Mode: OverrideExtend}},
+		"nothing set":       {"2026-12-24": {}},
+		"bad mode":          {"2026-12-24": {Mode: "merge", Sessions: session}},
+		"bad session":       {"2026-12-24": {Sessions: []WorkSession{{Start: "09:00", End: "09:00"}}}},
+		"overlap":           {"2026-12-20/2026-12-24": {Off: true}, "2026-12-24": {Sessions: session}},
+	} {
+		if err := ValidateOverrides(overrides); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestValidateConfig_CronWindows(t *testing.T) {
+	cfg := &APPConfig{
+		Scheduler: SchedulerConfig{Interval: (1 * time.Minute)},
+		IdlePrevention: IdlePreventionConfig{
+			Enabled:  true,
+			Interval: (5 * time.Minute),
+			Mode:     "mixed",
+		},
+		RetryPolicy: RetryPolicyConfig{RetryInterval: "10s"},
+		CronWindows: []string{`"0 8 * * 1-5" for 9h`, "0 9 * * MON#1 for 8h"},
+	}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid cron windows, got %v", err)
+	}
+	if len(cfg.CronSchedule) != 2 || cfg.CronSchedule[1].Duration != 8*time.Hour {
+		t.Errorf("Expected parsed cron windows kept on the config, got %v", cfg.CronSchedule)
+	}
+	for _, w := range []string{"0 8 * * 1-5", "0 8 * * 1-5 for 0s", "0 25 * * * for 1h"} {
+		cfg.CronWindows = []string{w}
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("Expected error for cron window %q, got nil", w)
+		}
+	}
+}
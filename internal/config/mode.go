@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"sync"
+)
+
+// ModeValidator 檢查特定模式所需的設定欄位，nil 代表該模式不需額外檢查
+type ModeValidator func(cfg *IdlePreventionConfig) error
+
+var (
+	modesMu   sync.RWMutex
+	modes     = map[string]ModeValidator{}
+	modeOrder []string
+)
+
+// 內建模式不需額外的設定檢查；preventidle 會再為其註冊執行方式
+func init() {
+	for _, name := range []string{"key", "mouse", "mixed", "assert"} {
+		RegisterMode(name, nil)
+	}
+}
+
+// RegisterMode 以名稱註冊閒置防護模式及其設定驗證函式，重複註冊會覆蓋先前的驗證函式。
+// 模式的執行方式由 preventidle.RegisterMode 一併註冊。
+func RegisterMode(name string, validate ModeValidator) {
+	modesMu.Lock()
+	defer modesMu.Unlock()
+	if _, ok := modes[name]; !ok {
+		modeOrder = append(modeOrder, name)
+	}
+	modes[name] = validate
+}
+
+// ModeNames 回傳所有已註冊的模式名稱（依註冊順序）
+func ModeNames() []string {
+	modesMu.RLock()
+	defer modesMu.RUnlock()
+	return append([]string(nil), modeOrder...)
+}
+
+// LookupMode 回傳名為 name 的模式驗證函式；未註冊的模式回傳 *InvalidModeError
+func LookupMode(name string) (ModeValidator, error) {
+	modesMu.RLock()
+	validate, ok := modes[name]
+	modesMu.RUnlock()
+	if !ok {
+		return nil, &InvalidModeError{
+			Mode:    name,
+			Message: "Invalid idle prevention mode; must be one of: " + strings.Join(ModeNames(), ", "),
+		}
+	}
+	return validate, nil
+}
+
+// ValidateMode 確認 cfg.Mode 已註冊，並執行該模式的驗證函式
+func ValidateMode(cfg *IdlePreventionConfig) error {
+	validate, err := LookupMode(cfg.Mode)
+	if err != nil {
+		return err
+	}
+	if validate == nil {
+		return nil
+	}
+	return validate(cfg)
+}
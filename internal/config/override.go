@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 特定日期設定的合併方式
+const (
+	OverrideReplace = "replace"
+	OverrideExtend  = "extend"
+)
+
+// overrideDateLayout 為 overrides 鍵的日期格式
+const overrideDateLayout = "2006-01-02"
+
+// ParseOverrideDates 解析 overrides 的鍵，回傳區間的第一天與最後一天（UTC 午夜，僅代表日曆日期）
+func ParseOverrideDates(key string) (time.Time, time.Time, error) {
+	from, to, isRange := strings.Cut(key, "/")
+	start, err := time.Parse(overrideDateLayout, strings.TrimSpace(from))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid overrides date %q: want YYYY-MM-DD or YYYY-MM-DD/YYYY-MM-DD", key)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := time.Parse(overrideDateLayout, strings.TrimSpace(to))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid overrides date %q: want YYYY-MM-DD or YYYY-MM-DD/YYYY-MM-DD", key)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid overrides date range %q: end is before start", key)
+	}
+	return start, end, nil
+}
+
+// Lookup 回傳涵蓋 day 日期（依 day 本身的時區）的設定
+func (o ScheduleOverrides) Lookup(day time.Time) (ScheduleOverride, bool) {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	for key, override := range o {
+		start, end, err := ParseOverrideDates(key)
+		if err != nil {
+			continue
+		}
+		if !date.Before(start) && !date.After(end) {
+			return override, true
+		}
+	}
+	return ScheduleOverride{}, false
+}
+
+// ValidateOverrides 檢查日期格式、每個設定本身是否矛盾，以及不同的鍵是否涵蓋同一天
+func ValidateOverrides(overrides ScheduleOverrides) error {
+	type span struct {
+		key        string
+		start, end time.Time
+	}
+	var spans []span
+	for key, o := range overrides {
+		start, end, err := ParseOverrideDates(key)
+		if err != nil {
+			return err
+		}
+		switch o.Mode {
+		case "", OverrideReplace, OverrideExtend:
+		default:
+			return fmt.Errorf("invalid overrides.%s mode %q: must be %s or %s", key, o.Mode, OverrideReplace, OverrideExtend)
+		}
+		switch {
+		case o.Off && (len(o.Sessions) > 0 || o.Mode == OverrideExtend):
+			return fmt.Errorf("overrides.%s is marked off but also sets sessions or extend mode", key)
+		case !o.Off && len(o.Sessions) == 0:
+			return fmt.Errorf("overrides.%s must set off: true or list sessions", key)
+		}
+		if err := validateSessions("overrides."+key, o.Sessions); err != nil {
+			return err
+		}
+		spans = append(spans, span{key, start, end})
+	}
+
+	// 依開始日期排序後，相鄰區間重疊即代表同一天有兩個設定
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	for i := 1; i < len(spans); i++ {
+		if !spans[i].start.After(spans[i-1].end) {
+			return fmt.Errorf("overrides %q and %q overlap", spans[i-1].key, spans[i].key)
+		}
+	}
+	return nil
+}
+
+// validateSessions 檢查工作時段的時區與時間格式；where 為錯誤訊息中的設定位置，例如 "workSchedule.monday"
+func validateSessions(where string, sessions []WorkSession) error {
+	for _, session := range sessions {
+		if session.Timezone != "" {
+			if _, err := time.LoadLocation(session.Timezone); err != nil {
+				return fmt.Errorf("invalid %s timezone (%s): %w", where, session.Timezone, err)
+			}
+		}
+		start, err := time.Parse("15:04", session.Start)
+		if err != nil {
+			return fmt.Errorf("invalid %s start time (%s): %w", where, session.Start, err)
+		}
+		end, err := time.Parse("15:04", session.End)
+		if err != nil {
+			return fmt.Errorf("invalid %s end time (%s): %w", where, session.End, err)
+		}
+		// 結束時間早於開始時間代表跨越午夜的時段（例如 22:00–06:00），結束於隔天
+		if start.Equal(end) {
+			return fmt.Errorf("in %s, start time (%s) must differ from end time (%s)", where, session.Start, session.End)
+		}
+	}
+	return nil
+}
@@ -35,3 +35,229 @@ func ParseJSONConfig(data []byte) (*APPConfig, error) {
 func MarshalJSON(cfg *APPConfig) ([]byte, error) {
 	return json.MarshalIndent(cfg, "", "  ")
 }
+
+// UnmarshalYAML 允許 backend 寫成單一字串（例如 "auto"）或字串陣列
+func (b *BackendList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var name string
+		if err := value.Decode(&name); err != nil {
+			return err
+		}
+		*b = BackendList{name}
+		return nil
+	}
+	var names []string
+	if err := value.Decode(&names); err != nil {
+		return err
+	}
+	*b = names
+	return nil
+}
+
+// MarshalYAML 單一後端時輸出為字串，否則輸出為陣列
+func (b BackendList) MarshalYAML() (interface{}, error) {
+	if len(b) == 1 {
+		return b[0], nil
+	}
+	return []string(b), nil
+}
+
+// UnmarshalJSON 允許 backend 寫成單一字串或字串陣列
+func (b *BackendList) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*b = BackendList{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*b = names
+	return nil
+}
+
+// IntervalAutoValue 為 idlePrevention.interval 的自動值
+const IntervalAutoValue = "auto"
+
+// UnmarshalYAML 允許 idlePrevention.interval 寫成 "auto"，其餘欄位照常解碼
+func (c *IdlePreventionConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain IdlePreventionConfig
+	auto := false
+	if value.Kind == yaml.MappingNode {
+		node := *value
+		node.Content = nil
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			k, v := value.Content[i], value.Content[i+1]
+			if k.Value == "interval" && v.Kind == yaml.ScalarNode && v.Value == IntervalAutoValue {
+				auto = true
+				continue
+			}
+			node.Content = append(node.Content, k, v)
+		}
+		value = &node
+	}
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	c.IntervalAuto = auto
+	return nil
+}
+
+// MarshalYAML 在 interval 為 auto 時輸出 "auto"，而非執行期推算出的值
+func (c IdlePreventionConfig) MarshalYAML() (interface{}, error) {
+	type plain IdlePreventionConfig
+	var node yaml.Node
+	if err := node.Encode(plain(c)); err != nil {
+		return nil, err
+	}
+	if c.IntervalAuto {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "interval" {
+				node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: IntervalAutoValue}
+			}
+		}
+	}
+	return &node, nil
+}
+
+// UnmarshalJSON 允許 idlePrevention.interval 寫成 "auto"
+func (c *IdlePreventionConfig) UnmarshalJSON(data []byte) error {
+	type plain IdlePreventionConfig
+	aux := struct {
+		*plain
+		Interval json.RawMessage `json:"interval"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.IntervalAuto = string(aux.Interval) == `"`+IntervalAutoValue+`"`
+	if c.IntervalAuto || len(aux.Interval) == 0 {
+		return nil
+	}
+	return json.Unmarshal(aux.Interval, &c.Interval)
+}
+
+// MarshalJSON 在 interval 為 auto 時輸出 "auto"
+func (c IdlePreventionConfig) MarshalJSON() ([]byte, error) {
+	type plain IdlePreventionConfig
+	if !c.IntervalAuto {
+		return json.Marshal(plain(c))
+	}
+	return json.Marshal(struct {
+		plain
+		Interval string `json:"interval"`
+	}{plain(c), IntervalAutoValue})
+}
+
+// WorkScheduleTimezoneKey 為 workSchedule 中指定時區的鍵，與星期名稱並列
+const WorkScheduleTimezoneKey = "timezone"
+
+// UnmarshalYAML 取出 workSchedule.timezone 存入 Timezone，其餘欄位照常解碼
+func (c *APPConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain APPConfig
+	timezone := ""
+	if value.Kind == yaml.MappingNode {
+		node := *value
+		node.Content = append([]*yaml.Node(nil), value.Content...)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if k.Value != "workSchedule" || v.Kind != yaml.MappingNode {
+				continue
+			}
+			schedule := *v
+			schedule.Content = nil
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				if v.Content[j].Value == WorkScheduleTimezoneKey {
+					if err := v.Content[j+1].Decode(&timezone); err != nil {
+						return err
+					}
+					continue
+				}
+				schedule.Content = append(schedule.Content, v.Content[j], v.Content[j+1])
+			}
+			node.Content[i+1] = &schedule
+		}
+		value = &node
+	}
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	c.Timezone = timezone
+	return nil
+}
+
+// MarshalYAML 把 Timezone 輸出為 workSchedule.timezone
+func (c APPConfig) MarshalYAML() (interface{}, error) {
+	type plain APPConfig
+	var node yaml.Node
+	if err := node.Encode(plain(c)); err != nil {
+		return nil, err
+	}
+	if c.Timezone == "" {
+		return &node, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "workSchedule" {
+			continue
+		}
+		schedule := node.Content[i+1]
+		if schedule.Kind != yaml.MappingNode {
+			// 沒有任何工作時段時 map 會輸出為 null
+			schedule = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content[i+1] = schedule
+		}
+		schedule.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: WorkScheduleTimezoneKey},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: c.Timezone},
+		}, schedule.Content...)
+	}
+	return &node, nil
+}
+
+// UnmarshalJSON 取出 workSchedule.timezone 存入 Timezone
+func (c *APPConfig) UnmarshalJSON(data []byte) error {
+	type plain APPConfig
+	aux := struct {
+		*plain
+		WorkSchedule map[string]json.RawMessage `json:"workSchedule"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Timezone = ""
+	if raw, ok := aux.WorkSchedule[WorkScheduleTimezoneKey]; ok {
+		if err := json.Unmarshal(raw, &c.Timezone); err != nil {
+			return err
+		}
+		delete(aux.WorkSchedule, WorkScheduleTimezoneKey)
+	}
+	if aux.WorkSchedule == nil {
+		return nil
+	}
+	c.WorkSchedule = WorkSchedule{}
+	for day, raw := range aux.WorkSchedule {
+		var sessions []WorkSession
+		if err := json.Unmarshal(raw, &sessions); err != nil {
+			return err
+		}
+		c.WorkSchedule[day] = sessions
+	}
+	return nil
+}
+
+// MarshalJSON 把 Timezone 輸出為 workSchedule.timezone
+func (c APPConfig) MarshalJSON() ([]byte, error) {
+	type plain APPConfig
+	if c.Timezone == "" {
+		return json.Marshal(plain(c))
+	}
+	schedule := map[string]interface{}{WorkScheduleTimezoneKey: c.Timezone}
+	for day, sessions := range c.WorkSchedule {
+		schedule[day] = sessions
+	}
+	return json.Marshal(struct {
+		plain
+		WorkSchedule map[string]interface{} `json:"workSchedule"`
+	}{plain(c), schedule})
+}
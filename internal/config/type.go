@@ -1,6 +1,10 @@
 package config
 
-import "time"
+import (
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/cron"
+)
 
 // Config 定義了從 config.yaml 讀取的整個設定結構
 type APPConfig struct {
@@ -10,6 +14,11 @@ type APPConfig struct {
 	Logging        LoggingConfig        `yaml:"logging" json:"logging"`
 	RetryPolicy    RetryPolicyConfig    `yaml:"retryPolicy" json:"retryPolicy"`
 	WorkSchedule   WorkSchedule         `yaml:"workSchedule" json:"workSchedule"`
+	Timezone       string               `yaml:"-" json:"-"` // workSchedule.timezone：工作時段使用的 IANA 時區，空字串代表系統時區
+	Holidays       HolidaysConfig       `yaml:"holidays" json:"holidays"`
+	Overrides      ScheduleOverrides    `yaml:"overrides" json:"overrides"`
+	CronWindows    []string             `yaml:"cronWindows" json:"cronWindows"` // 例如 `"0 8 * * 1-5" for 9h`，與 workSchedule 並用
+	CronSchedule   []*cron.Window       `yaml:"-" json:"-"`                     // 由 ValidateConfig 解析 CronWindows 而得；為 nil 時排程判斷自行解析 CronWindows
 }
 
 type VersionConfig struct {
@@ -18,15 +27,136 @@ type VersionConfig struct {
 }
 
 type IdlePreventionConfig struct {
-	Enabled  bool          `yaml:"enabled" json:"enabled"`
-	Interval time.Duration `yaml:"interval" json:"interval"` // 例如 "5m"
-	Mode     string        `yaml:"mode" json:"mode"`         // 可選值： "key"、"mouse"、"mixed"
+	Enabled       bool               `yaml:"enabled" json:"enabled"`
+	Interval      time.Duration      `yaml:"interval" json:"interval"` // 例如 "5m"，或 "auto" 依螢幕保護逾時自動調整
+	IntervalAuto  bool               `yaml:"-" json:"-"`               // interval 設為 "auto"，此時 Interval 為目前生效的值
+	AutoInterval  AutoIntervalConfig `yaml:"autoInterval" json:"autoInterval"`
+	Mode          string             `yaml:"mode" json:"mode"`                 // 內建 "key"、"mouse"、"mixed"、"assert"，其他模式可透過 RegisterMode 註冊
+	Backend       BackendList        `yaml:"backend" json:"backend"`           // "auto" 或依序嘗試的後端清單，例如 [uinput, xtest, logind]
+	Actions       []ActionConfig     `yaml:"actions" json:"actions"`           // 自訂模擬動作，空代表依 mode 使用預設動作
+	AllowVisible  bool               `yaml:"allowVisible" json:"allowVisible"` // 允許會產生可見文字或點擊的動作
+	Jitter        JitterConfig       `yaml:"jitter" json:"jitter"`
+	Macros        map[string]string  `yaml:"macros" json:"macros"`               // 具名的活動巨集，例如 "press shift; wait 50ms; move 3,0"
+	Macro         string             `yaml:"macro" json:"macro"`                 // 要執行的巨集名稱，設定後取代 mode 與 actions
+	DryRun        bool               `yaml:"dryRun" json:"dryRun"`               // 只把預計的動作寫入日誌，不實際注入輸入或持有電源鎖
+	DryRunJournal string             `yaml:"dryRunJournal" json:"dryRunJournal"` // dry-run 的 JSON-lines 日誌檔，空字串代表 "dry-run.jsonl"
+	RateLimit     RateLimitConfig    `yaml:"rateLimit" json:"rateLimit"`
+	Focus         FocusConfig        `yaml:"focus" json:"focus"`
+	Replay        ReplayConfig       `yaml:"replay" json:"replay"`
+	Uinput        UinputConfig       `yaml:"uinput" json:"uinput"`
+	X11           X11Config          `yaml:"x11" json:"x11"`
+	Logind        LogindConfig       `yaml:"logind" json:"logind"`
+	ScreenSaver   ScreenSaverConfig  `yaml:"screensaver" json:"screensaver"`
+	Evdev         EvdevConfig        `yaml:"evdev" json:"evdev"`
+}
+
+// AutoIntervalConfig 定義 interval: auto 時如何由螢幕保護／DPMS 逾時推算閒置門檻
+type AutoIntervalConfig struct {
+	Fraction float64       `yaml:"fraction" json:"fraction"` // 取逾時的比例，預設 0.5
+	Recheck  time.Duration `yaml:"recheck" json:"recheck"`   // 重新讀取逾時的間隔，預設 10m
+	Fallback time.Duration `yaml:"fallback" json:"fallback"` // 讀不到逾時時使用的門檻，預設 5m
+	DconfDir string        `yaml:"dconfDir" json:"dconfDir"` // dconf keyfile 資料庫目錄，預設 "/etc/dconf/db"
+}
+
+// ReplayConfig 定義 replay 模式如何重播以 record 指令錄製的活動節奏
+type ReplayConfig struct {
+	Profile   string        `yaml:"profile" json:"profile"`     // 節奏檔路徑
+	MaxGap    time.Duration `yaml:"maxGap" json:"maxGap"`       // 一次模擬內動作的最大間隔，抽到更長的間隔即結束，預設 5s
+	MaxEvents int           `yaml:"maxEvents" json:"maxEvents"` // 一次模擬最多送出的動作數，預設 10
+}
+
+// RateLimitConfig 定義模擬活動的頻率上限，每個送出的動作算一個事件，0 代表不限制。
+// 每小時與每天的上限以整點與午夜起算的固定時窗計算。
+// 達到上限時略過模擬，改以電源鎖保持喚醒。
+type RateLimitConfig struct {
+	PerMinute int `yaml:"perMinute" json:"perMinute"`
+	PerHour   int `yaml:"perHour" json:"perHour"`
+	PerDay    int `yaml:"perDay" json:"perDay"`
+}
+
+// X11Config 定義 X11 (xtest) 後端的連線設定
+type X11Config struct {
+	Display string `yaml:"display" json:"display"` // 空字串代表使用 $DISPLAY
+}
+
+// LogindConfig 定義 systemd-logind 後端的 D-Bus 設定
+type LogindConfig struct {
+	BusAddress string `yaml:"busAddress" json:"busAddress"` // 空字串代表系統匯流排
+	Session    string `yaml:"session" json:"session"`       // 空字串代表 $XDG_SESSION_ID 或目前行程的工作階段
+}
+
+// ScreenSaverConfig 定義 freedesktop ScreenSaver / GNOME SessionManager 後端的 D-Bus 設定
+type ScreenSaverConfig struct {
+	BusAddress string `yaml:"busAddress" json:"busAddress"` // 空字串代表目前使用者的 session bus
+}
+
+// JitterConfig 定義讓模擬活動不那麼規律的隨機化設定
+type JitterConfig struct {
+	IntervalPercent float64            `yaml:"intervalPercent" json:"intervalPercent"` // 排程與閒置門檻的隨機幅度（±%），0 代表固定
+	Weights         map[string]float64 `yaml:"weights" json:"weights"`                 // 每次只依權重選一種動作，例如 {key: 1, mouse: 3}
+	MinDelay        time.Duration      `yaml:"minDelay" json:"minDelay"`               // 動作之間的隨機延遲下限
+	MaxDelay        time.Duration      `yaml:"maxDelay" json:"maxDelay"`               // 動作之間的隨機延遲上限
+	Seed            uint64             `yaml:"seed" json:"seed"`                       // 亂數種子，0 代表每次啟動隨機
+}
+
+// ActionConfig 定義一個模擬輸入動作
+type ActionConfig struct {
+	Type   string `yaml:"type" json:"type"` // "key"、"mouse" 或 "click"
+	Key    string `yaml:"key" json:"key"`   // type 為 key 時的按鍵名稱，例如 "F15"、"Shift"、"ScrollLock"
+	DX     int32  `yaml:"dx" json:"dx"`     // type 為 mouse 時的相對位移，移動後會移回原位
+	DY     int32  `yaml:"dy" json:"dy"`
+	Button string `yaml:"button" json:"button"` // type 為 click 時的按鍵："left"（預設）、"right"、"middle"
+
+	// type 為 mouse 時可改用移動樣式："out-and-back"、"circle"、"random-walk"、"return-to-origin"
+	Pattern   string        `yaml:"pattern" json:"pattern"`
+	Radius    int32         `yaml:"radius" json:"radius"`       // 樣式半徑（像素），預設 3
+	Steps     int           `yaml:"steps" json:"steps"`         // 樣式步數，預設 8
+	StepDelay time.Duration `yaml:"stepDelay" json:"stepDelay"` // 每步之間的等待時間，例如 "20ms"
+}
+
+// FocusConfig 定義依焦點視窗抑制模擬輸入的規則（目前支援 X11）。
+// 樣式為 path.Match 語法，不分大小寫，比對視窗的 WM_CLASS（instance 或 class）與標題。
+type FocusConfig struct {
+	Allow  []string `yaml:"allow" json:"allow"`   // 只在符合的視窗送出模擬輸入，空代表不限制
+	Deny   []string `yaml:"deny" json:"deny"`     // 符合的視窗不送出模擬輸入，例如 "*terminal*"、"zoom"
+	OnDeny string   `yaml:"onDeny" json:"onDeny"` // "skip"（預設）略過本次模擬，"mouse" 改為只移動滑鼠
+}
+
+// EvdevConfig 定義 evdev 閒置偵測後端監看的輸入裝置
+type EvdevConfig struct {
+	Dir      string   `yaml:"dir" json:"dir"`           // 預設 "/dev/input"
+	SysfsDir string   `yaml:"sysfsDir" json:"sysfsDir"` // 讀取裝置名稱的位置，預設 "/sys/class/input"
+	Include  []string `yaml:"include" json:"include"`   // 裝置名稱或檔名的樣式，空代表全部
+	Exclude  []string `yaml:"exclude" json:"exclude"`   // 本程式的 uinput 虛擬裝置一律排除
+}
+
+// BackendList 為依序嘗試的後端名稱，YAML／JSON 中可寫成單一字串或字串陣列
+type BackendList []string
+
+// UinputConfig 定義 Linux uinput 虛擬輸入裝置的設定
+type UinputConfig struct {
+	Device string `yaml:"device" json:"device"` // 預設 "/dev/uinput"
 }
 
 type SchedulerConfig struct {
 	Interval time.Duration `yaml:"interval" json:"interval"` // 例如 "10m"
 }
 
+// ScheduleOverride 定義特定日期的工作時段，優先於 workSchedule 中該星期的設定
+type ScheduleOverride struct {
+	Off      bool          `yaml:"off" json:"off"`           // 整天不工作
+	Mode     string        `yaml:"mode" json:"mode"`         // "replace"（預設）取代當天的時段，"extend" 加在當天的時段之後
+	Sessions []WorkSession `yaml:"sessions" json:"sessions"` // 結束時間早於開始時間時跨越午夜
+}
+
+// ScheduleOverrides 以 ISO 日期（2026-12-24）或日期區間（2026-12-27/2026-12-31，含兩端）對應特定日期的設定
+type ScheduleOverrides map[string]ScheduleOverride
+
+// HolidaysConfig 定義非工作時間的 iCalendar 來源；檔案中的事件期間優先於 workSchedule
+type HolidaysConfig struct {
+	Calendars []string `yaml:"calendars" json:"calendars"` // 本機 .ics 檔案路徑，檔案變更時自動重新載入
+}
+
 type LoggingConfig struct {
 	Level  string `yaml:"level" json:"level"`   // 例如 "info" 或 "debug"
 	Output string `yaml:"output" json:"output"` // "console" 或檔案路徑
@@ -37,15 +167,20 @@ type RetryPolicyConfig struct {
 	RetryInterval string `yaml:"retryInterval" json:"retryInterval"` // 例如 "10s"
 }
 
+// InvalidModeError 表示設定的模式未註冊
 type InvalidModeError struct {
+	Mode    string
 	Message string
 }
 
-// WorkSession 定義一天內單個工作時段的開始與結束時間
+// WorkSession 定義一天內單個工作時段的開始與結束時間；
+// 結束時間早於開始時間時為跨越午夜的時段，結束於隔天
 type WorkSession struct {
-	Start string `yaml:"start" json:"start"`
-	End   string `yaml:"end" json:"end"`
+	Start    string `yaml:"start" json:"start"`
+	End      string `yaml:"end" json:"end"`
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"` // 覆寫 workSchedule.timezone 的 IANA 時區
 }
 
-// WorkSchedule 定義一週內每天的工作時段，使用 map 對應每一天的時段陣列
+// WorkSchedule 定義一週內每天的工作時段，使用 map 對應每一天的時段陣列。
+// 設定檔中同一層的 timezone 鍵解碼至 APPConfig.Timezone。
 type WorkSchedule map[string][]WorkSession
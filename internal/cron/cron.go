@@ -0,0 +1,240 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression 為解析後的五欄 cron 運算式：分 時 日 月 星期。
+// 除了 *、?、清單、範圍與間隔（*/15、1-5/2）外，日欄支援 L（當月最後一天），
+// 星期欄支援 5L（當月最後一個週五）與 1#1（當月第一個週一）。
+// 日與星期兩欄都有限制時，符合其中之一即可（與 Vixie cron 相同）。
+// 運算式本身沒有以週為間隔的寫法；「每隔一週」由 Window 的 every <n>w from <日期> 表示。
+type Expression struct {
+	minutes [60]bool
+	hours   [24]bool
+	dom     [32]bool
+	months  [13]bool
+	dow     [7]bool
+
+	lastDOM bool       // 日欄的 L
+	lastDOW [7]bool    // 星期欄的 nL
+	nthDOW  [7][6]bool // 星期欄的 n#k，k 為 1–5
+	domStar bool       // 日欄為 * 或 ?
+	dowStar bool       // 星期欄為 * 或 ?
+	source  string
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var dayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// Parse 解析五欄 cron 運算式
+func Parse(expr string) (*Expression, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+	e := &Expression{source: strings.Join(fields, " ")}
+	if err := parseField(fields[0], 0, 59, nil, e.minutes[:]); err != nil {
+		return nil, fmt.Errorf("cron expression %q: minute: %w", expr, err)
+	}
+	if err := parseField(fields[1], 0, 23, nil, e.hours[:]); err != nil {
+		return nil, fmt.Errorf("cron expression %q: hour: %w", expr, err)
+	}
+	if err := e.parseDOM(fields[2]); err != nil {
+		return nil, fmt.Errorf("cron expression %q: day-of-month: %w", expr, err)
+	}
+	if err := parseField(fields[3], 1, 12, monthNames, e.months[:]); err != nil {
+		return nil, fmt.Errorf("cron expression %q: month: %w", expr, err)
+	}
+	if err := e.parseDOW(fields[4]); err != nil {
+		return nil, fmt.Errorf("cron expression %q: day-of-week: %w", expr, err)
+	}
+	return e, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+func (e *Expression) parseDOM(field string) error {
+	e.domStar = field == "*" || field == "?"
+	var rest []string
+	for _, item := range strings.Split(field, ",") {
+		if strings.EqualFold(item, "L") {
+			e.lastDOM = true
+			continue
+		}
+		rest = append(rest, item)
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	return parseField(strings.Join(rest, ","), 1, 31, nil, e.dom[:])
+}
+
+func (e *Expression) parseDOW(field string) error {
+	e.dowStar = field == "*" || field == "?"
+	var rest []string
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case upper == "L":
+			return fmt.Errorf("%q is ambiguous, use nL for the last given weekday of the month (e.g. 5L)", item)
+		case strings.HasSuffix(upper, "L"):
+			day, err := parseValue(upper[:len(upper)-1], 0, 7, dayNames)
+			if err != nil {
+				return err
+			}
+			e.lastDOW[day%7] = true
+		case strings.Contains(upper, "#"):
+			d, k, _ := strings.Cut(upper, "#")
+			day, err := parseValue(d, 0, 7, dayNames)
+			if err != nil {
+				return err
+			}
+			nth, err := strconv.Atoi(k)
+			if err != nil || nth < 1 || nth > 5 {
+				return fmt.Errorf("invalid occurrence in %q: must be 1-5", item)
+			}
+			e.nthDOW[day%7][nth] = true
+		default:
+			rest = append(rest, item)
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	var days [8]bool
+	if err := parseField(strings.Join(rest, ","), 0, 7, dayNames, days[:]); err != nil {
+		return err
+	}
+	for d, ok := range days {
+		if ok {
+			e.dow[d%7] = true // 7 與 0 皆為週日
+		}
+	}
+	return nil
+}
+
+// parseField 解析以逗號分隔的清單，每項為 *、值、範圍 a-b，可附加 /step
+func parseField(field string, lo, hi int, names map[string]int, set []bool) error {
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return fmt.Errorf("invalid step in %q", item)
+			}
+		}
+
+		from, to := lo, hi
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = parseValue(a, lo, hi, names); err != nil {
+				return err
+			}
+			if to, err = parseValue(b, lo, hi, names); err != nil {
+				return err
+			}
+			if to < from {
+				return fmt.Errorf("invalid range %q: end is before start", rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart, lo, hi, names)
+			if err != nil {
+				return err
+			}
+			from = v
+			if !hasStep {
+				to = v // a/step 代表從 a 到上限
+			}
+		}
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+func parseValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, lo, hi)
+	}
+	return v, nil
+}
+
+// MatchDay 判斷 d 所在的日期是否符合日、月與星期欄
+func (e *Expression) MatchDay(d time.Time) bool {
+	if !e.months[d.Month()] {
+		return false
+	}
+	last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+	domMatch := e.dom[d.Day()] || (e.lastDOM && d.Day() == last)
+
+	wd := d.Weekday()
+	dowMatch := e.dow[wd] ||
+		e.nthDOW[wd][(d.Day()-1)/7+1] ||
+		(e.lastDOW[wd] && d.Day()+7 > last)
+
+	switch {
+	case e.domStar && e.dowStar:
+		return true
+	case e.domStar:
+		return dowMatch
+	case e.dowStar:
+		return domMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Each 依時間順序回報 (from, to] 之間的每一個觸發時刻（依 from 的時區解讀）；yield 回傳 false 時停止
+func (e *Expression) Each(from, to time.Time, yield func(time.Time) bool) {
+	loc := from.Location()
+	to = to.In(loc)
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !e.MatchDay(day) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			if !e.hours[h] {
+				continue
+			}
+			for m := 0; m < 60; m++ {
+				if !e.minutes[m] {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, loc)
+				if !t.After(from) {
+					continue
+				}
+				if t.After(to) {
+					return
+				}
+				if !yield(t) {
+					return
+				}
+			}
+		}
+	}
+}
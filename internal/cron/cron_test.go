@@ -0,0 +1,149 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpression_MatchDay(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	for _, tt := range []struct {
+		expr string
+		day  time.Time
+		want bool
+	}{
+		{"0 8 * * 1-5", day(2026, time.October, 16), true},    // friday
+		{"0 8 * * 1-5", day(2026, time.October, 17), false},   // saturday
+		{"0 8 * * MON#1", day(2026, time.October, 5), true},   // 第一個週一
+		{"0 8 * * MON#1", day(2026, time.October, 12), false}, // 第二個週一
+		{"0 8 * * 5L", day(2026, time.October, 30), true},     // 最後一個週五
+		{"0 8 * * 5L", day(2026, time.October, 23), false},
+		{"0 8 * * 5#1,5#3", day(2026, time.October, 16), true},  // 每月第一與第三個週五：第三個週五
+		{"0 8 * * 5#1,5#3", day(2026, time.October, 30), false}, // 第五個週五不符合
+		{"0 8 L * *", day(2028, time.February, 29), true},       // 閏年的月底
+		{"0 8 L * *", day(2028, time.February, 28), false},
+		{"0 8 1,15 * 0", day(2026, time.October, 15), true}, // 日與星期兩欄取聯集
+		{"0 8 1,15 * 0", day(2026, time.October, 18), true}, // sunday
+		{"0 8 1,15 * 0", day(2026, time.October, 16), false},
+		{"0 8 * JAN-MAR/2 7", day(2026, time.March, 1), true},     // 7 為週日
+		{"0 8 * JAN-MAR/2 7", day(2026, time.February, 1), false}, // 二月不在 1、3 月
+	} {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+		}
+		if got := e.MatchDay(tt.day); got != tt.want {
+			t.Errorf("%q MatchDay(%s) = %v, want %v", tt.expr, tt.day.Format("2006-01-02 Mon"), got, tt.want)
+		}
+	}
+}
+
+func TestExpression_Each(t *testing.T) {
+	e, err := Parse("*/20 9-10 * * *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	from := time.Date(2026, time.October, 16, 9, 20, 0, 0, time.UTC)
+	var got []string
+	e.Each(from, from.Add(time.Hour), func(t time.Time) bool {
+		got = append(got, t.Format("15:04"))
+		return true
+	})
+	if strings.Join(got, " ") != "09:40 10:00 10:20" {
+		t.Errorf("Unexpected triggers %v", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"0 8 * *",     // 欄位不足
+		"60 8 * * *",  // 分鐘超出範圍
+		"0 8 0 * *",   // 日從 1 開始
+		"0 8 * * 1#6", // 第六個
+		"0 8 * * L",   // 星期欄單獨的 L 含意不明
+		"0 8 * * 5-1", // 反向範圍
+		"0 8 */0 * *", // 間隔為 0
+		"0 8 * FOO *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected error for %q, got nil", expr)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w, err := ParseWindow(`"0 22 * * FRI" for 9h`)
+	if err != nil {
+		t.Fatalf("ParseWindow failed: %v", err)
+	}
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, time.October, 16, 22, 0, 0, 0, time.UTC), true}, // friday 開始
+		{time.Date(2026, time.October, 17, 6, 59, 0, 0, time.UTC), true}, // 跨過午夜
+		{time.Date(2026, time.October, 17, 7, 0, 0, 0, time.UTC), false}, // 不含結束時刻
+		{time.Date(2026, time.October, 16, 21, 59, 0, 0, time.UTC), false},
+	} {
+		if got := w.Covers(tt.at); got != tt.want {
+			t.Errorf("Covers(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"0 8 * * 1-5", "0 8 * * 1-5 for", "0 8 * * 1-5 for -1h", "0 8 * * 9 for 1h"} {
+		if _, err := ParseWindow(s); err == nil {
+			t.Errorf("Expected error for %q, got nil", s)
+		}
+	}
+}
+
+func TestWindow_EveryOtherWeek(t *testing.T) {
+	w, err := ParseWindow(`"0 8 * * 5" for 9h every 2w from 2026-01-02`)
+	if err != nil {
+		t.Fatalf("ParseWindow failed: %v", err)
+	}
+	if got := w.String(); got != `"0 8 * * 5" for 9h0m0s every 2w from 2026-01-02` {
+		t.Errorf("Unexpected String() %q", got)
+	}
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, time.January, 2, 12, 0, 0, 0, time.UTC), true},    // 起算日
+		{time.Date(2026, time.January, 9, 12, 0, 0, 0, time.UTC), false},   // 隔一週
+		{time.Date(2026, time.January, 16, 12, 0, 0, 0, time.UTC), true},   // 第 2 週
+		{time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC), false},  // 第 41 週
+		{time.Date(2026, time.October, 23, 12, 0, 0, 0, time.UTC), true},   // 第 42 週，是當月第四個週五
+		{time.Date(2025, time.December, 19, 12, 0, 0, 0, time.UTC), true},  // 起算日之前往回推算
+		{time.Date(2025, time.December, 26, 12, 0, 0, 0, time.UTC), false}, // 起算日前一週
+	} {
+		if got := w.Covers(tt.at); got != tt.want {
+			t.Errorf("Covers(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	// 週一到週五的時窗：起算日所在的 7 天內每天都符合，下一個 7 天都不符合
+	w, err = ParseWindow(`"0 8 * * 1-5" for 9h every 2w from 2026-01-05`)
+	if err != nil {
+		t.Fatalf("ParseWindow failed: %v", err)
+	}
+	if !w.Covers(time.Date(2026, time.January, 9, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expected friday of the anchor week covered")
+	}
+	if w.Covers(time.Date(2026, time.January, 12, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expected monday of the following week not covered")
+	}
+
+	for _, s := range []string{
+		"0 8 * * 5 for 9h every 2w",
+		"0 8 * * 5 for 9h every 0w from 2026-01-02",
+		"0 8 * * 5 for 9h every 2d from 2026-01-02",
+		"0 8 * * 5 for 9h every 2w from 2026-13-01",
+		"0 8 * * 5 every 2w from 2026-01-02",
+	} {
+		if _, err := ParseWindow(s); err == nil {
+			t.Errorf("Expected error for %q, got nil", s)
+		}
+	}
+}
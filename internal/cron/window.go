@@ -0,0 +1,111 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window 為 cron 運算式加上持續時間的工作時窗，例如 "0 8 * * 1-5" for 9h。
+// Every 大於 1 時只有自 Anchor 起算、週數可被 Every 整除的週才會觸發，例如隔週五：
+// "0 8 * * 5" for 9h every 2w from 2026-01-02
+type Window struct {
+	Expr     *Expression
+	Duration time.Duration
+	Every    int       // 以週為單位的間隔，0 或 1 代表每週
+	Anchor   time.Time // Every 的起算日（UTC 午夜）；自該日起每 7 天為一週，起算日所在的週為第 0 週
+}
+
+// ParseWindow 解析 `<cron 運算式> for <持續時間> [every <n>w from <YYYY-MM-DD>]`，運算式可用引號包住
+func ParseWindow(s string) (*Window, error) {
+	src := s
+	var every int
+	var anchor time.Time
+	if i := strings.LastIndex(s, " every "); i >= 0 {
+		var err error
+		every, anchor, err = parseEvery(s[i+len(" every "):])
+		if err != nil {
+			return nil, fmt.Errorf("cron window %q: %w", src, err)
+		}
+		s = s[:i]
+	}
+
+	i := strings.LastIndex(s, " for ")
+	if i < 0 {
+		return nil, fmt.Errorf("cron window %q must be written as \"<cron expression> for <duration>\"", src)
+	}
+	expr := strings.Trim(strings.TrimSpace(s[:i]), `"'`)
+	d, err := time.ParseDuration(strings.TrimSpace(s[i+len(" for "):]))
+	if err != nil {
+		return nil, fmt.Errorf("cron window %q: invalid duration: %w", src, err)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("cron window %q: duration must be > 0", src)
+	}
+	e, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Window{Expr: e, Duration: d, Every: every, Anchor: anchor}, nil
+}
+
+// parseEvery 解析 `<n>w from <YYYY-MM-DD>`
+func parseEvery(s string) (int, time.Time, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 || !strings.HasSuffix(fields[0], "w") || fields[1] != "from" {
+		return 0, time.Time{}, fmt.Errorf("week interval must be written as \"every <n>w from <YYYY-MM-DD>\"")
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(fields[0], "w"))
+	if err != nil || n < 1 {
+		return 0, time.Time{}, fmt.Errorf("invalid week interval %q: must be a positive number of weeks", fields[0])
+	}
+	anchor, err := time.Parse("2006-01-02", fields[2])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid week interval anchor %q: %w", fields[2], err)
+	}
+	return n, anchor, nil
+}
+
+func (w *Window) String() string {
+	s := fmt.Sprintf("%q for %v", w.Expr, w.Duration)
+	if w.Every > 1 {
+		s += fmt.Sprintf(" every %dw from %s", w.Every, w.Anchor.Format("2006-01-02"))
+	}
+	return s
+}
+
+// Starts 依序回報時窗涵蓋 t 的每一個開始時刻（t-Duration < start <= t）；yield 回傳 false 時停止
+func (w *Window) Starts(t time.Time, yield func(start time.Time) bool) {
+	w.Expr.Each(t.Add(-w.Duration), t, func(start time.Time) bool {
+		if !w.inWeek(start) {
+			return true
+		}
+		return yield(start)
+	})
+}
+
+// inWeek 判斷 start 的日期（依 start 的時區）是否落在 Every 指定的週
+func (w *Window) inWeek(start time.Time) bool {
+	if w.Every <= 1 {
+		return true
+	}
+	date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(date.Sub(w.Anchor) / (24 * time.Hour))
+	// 起算日之前的日期也依相同的週期往回推算
+	week := days / 7
+	if days < 0 && days%7 != 0 {
+		week--
+	}
+	return ((week%w.Every)+w.Every)%w.Every == 0
+}
+
+// Covers 判斷 t 是否落在任一次觸發後的持續時間內
+func (w *Window) Covers(t time.Time) bool {
+	covered := false
+	w.Starts(t, func(time.Time) bool {
+		covered = true
+		return false
+	})
+	return covered
+}
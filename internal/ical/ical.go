@@ -0,0 +1,466 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Event 為 VEVENT 中與排程有關的部分。沒有時區的日期或時刻（floating）以 UTC 儲存牆上時間，
+// 檢查時依呼叫端的時區解讀。
+type Event struct {
+	UID          string
+	Summary      string
+	Start        time.Time
+	End          time.Time // 不含；未指定時依 RFC 5545：日期型為隔天，日期時間型與 Start 相同
+	AllDay       bool
+	Floating     bool
+	Rule         *Rule
+	RDates       []time.Time
+	ExDates      []time.Time
+	RecurrenceID time.Time // 覆寫重複事件中某一次的事件才有值
+	Cancelled    bool
+}
+
+// Calendar 為解析後的 iCalendar 檔案
+type Calendar struct {
+	Events []*Event
+}
+
+// ParseError 描述 iCalendar 內容中的錯誤位置
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// property 為一行展開後的內容行：NAME;PARAM=VALUE:VALUE
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+	line   int
+}
+
+// ParseFile 讀取並解析 .ics 檔案
+func ParseFile(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cal, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cal, nil
+}
+
+// Parse 解析 RFC 5545 內容中的 VEVENT。VTIMEZONE 定義不會被解讀，
+// TZID 需為 IANA 時區名稱；無法載入的 TZID 視為 floating 時間。
+// 取消（STATUS:CANCELLED）的事件與被 RECURRENCE-ID 覆寫的那一次不會出現在結果中。
+func Parse(r io.Reader) (*Calendar, error) {
+	props, err := readProperties(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{}
+	var stack []string
+	var current []property
+	for _, p := range props {
+		switch p.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(p.value))
+			if strings.EqualFold(p.value, "VEVENT") {
+				current = nil
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(p.value) {
+				return nil, &ParseError{Line: p.line, Msg: fmt.Sprintf("unexpected END:%s", p.value)}
+			}
+			stack = stack[:len(stack)-1]
+			if strings.EqualFold(p.value, "VEVENT") {
+				e, err := parseEvent(current)
+				if err != nil {
+					return nil, err
+				}
+				cal.Events = append(cal.Events, e)
+			}
+			continue
+		}
+		// 只收集 VEVENT 本身的屬性，忽略其中的 VALARM 等子元件
+		if len(stack) > 0 && stack[len(stack)-1] == "VEVENT" {
+			current = append(current, p)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, &ParseError{Line: props[len(props)-1].line, Msg: fmt.Sprintf("missing END:%s", stack[len(stack)-1])}
+	}
+
+	// RECURRENCE-ID 覆寫的那一次從主事件中排除，由覆寫事件本身取代
+	masters := map[string]*Event{}
+	for _, e := range cal.Events {
+		if e.RecurrenceID.IsZero() {
+			masters[e.UID] = e
+		}
+	}
+	events := cal.Events[:0]
+	for _, e := range cal.Events {
+		if !e.RecurrenceID.IsZero() {
+			if m, ok := masters[e.UID]; ok {
+				m.ExDates = append(m.ExDates, m.frame(e.RecurrenceID, e.Floating))
+			}
+		}
+		if !e.Cancelled {
+			events = append(events, e)
+		}
+	}
+	cal.Events = events
+	return cal, nil
+}
+
+// readProperties 讀取內容行並展開折行（以空白或 tab 開頭的行接續上一行）
+func readProperties(r io.Reader) ([]property, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	var starts []int
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		starts = append(starts, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	props := make([]property, 0, len(lines))
+	for i, line := range lines {
+		p, err := parseContentLine(line)
+		if err != nil {
+			return nil, &ParseError{Line: starts[i], Msg: err.Error()}
+		}
+		p.line = starts[i]
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+// parseContentLine 解析 NAME;PARAM=VALUE;...:VALUE，參數值可用雙引號包住 : 與 ;
+func parseContentLine(line string) (property, error) {
+	p := property{params: map[string]string{}}
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return p, fmt.Errorf("malformed content line %q", line)
+	}
+	p.name = strings.ToUpper(line[:i])
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return p, fmt.Errorf("malformed parameter in %q", line)
+		}
+		key := strings.ToUpper(rest[:eq])
+		j := eq + 1
+		var value string
+		if j < len(rest) && rest[j] == '"' {
+			end := strings.IndexByte(rest[j+1:], '"')
+			if end < 0 {
+				return p, fmt.Errorf("unterminated quoted parameter in %q", line)
+			}
+			value = rest[j+1 : j+1+end]
+			j += end + 2
+		} else {
+			end := strings.IndexAny(rest[j:], ";:")
+			if end < 0 {
+				return p, fmt.Errorf("missing value in %q", line)
+			}
+			value = rest[j : j+end]
+			j += end
+		}
+		p.params[key] = value
+		i += 1 + j
+		if i >= len(line) {
+			return p, fmt.Errorf("missing value in %q", line)
+		}
+	}
+	if line[i] != ':' {
+		return p, fmt.Errorf("malformed content line %q", line)
+	}
+	p.value = line[i+1:]
+	return p, nil
+}
+
+// parseEvent 由 VEVENT 的屬性建立 Event
+func parseEvent(props []property) (*Event, error) {
+	e := &Event{}
+	var dtend, duration, rrule *property
+	for i := range props {
+		p := &props[i]
+		switch p.name {
+		case "UID":
+			e.UID = p.value
+		case "SUMMARY":
+			e.Summary = unescapeText(p.value)
+		case "STATUS":
+			e.Cancelled = strings.EqualFold(p.value, "CANCELLED")
+		case "DTSTART":
+			t, allDay, floating, err := parseTime(p.value, p.params)
+			if err != nil {
+				return nil, &ParseError{Line: p.line, Msg: err.Error()}
+			}
+			e.Start, e.AllDay, e.Floating = t, allDay, floating
+		case "DTEND":
+			dtend = p
+		case "DURATION":
+			duration = p
+		case "RRULE":
+			rrule = p
+		case "RECURRENCE-ID":
+			t, _, _, err := parseTime(p.value, p.params)
+			if err != nil {
+				return nil, &ParseError{Line: p.line, Msg: err.Error()}
+			}
+			e.RecurrenceID = t
+		}
+	}
+	if e.Start.IsZero() {
+		line := 0
+		if len(props) > 0 {
+			line = props[0].line
+		}
+		return nil, &ParseError{Line: line, Msg: fmt.Sprintf("event %q has no DTSTART", e.UID)}
+	}
+
+	switch {
+	case dtend != nil:
+		t, _, floating, err := parseTime(dtend.value, dtend.params)
+		if err != nil {
+			return nil, &ParseError{Line: dtend.line, Msg: err.Error()}
+		}
+		e.End = e.frame(t, floating)
+	case duration != nil:
+		d, err := parseDuration(duration.value)
+		if err != nil {
+			return nil, &ParseError{Line: duration.line, Msg: err.Error()}
+		}
+		e.End = e.Start.Add(d)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+	if e.End.Before(e.Start) {
+		return nil, &ParseError{Line: props[0].line, Msg: fmt.Sprintf("event %q ends before it starts", e.UID)}
+	}
+
+	if rrule != nil {
+		rule, err := ParseRule(rrule.value, e.Start, e.Floating)
+		if err != nil {
+			return nil, &ParseError{Line: rrule.line, Msg: err.Error()}
+		}
+		e.Rule = rule
+	}
+	for _, p := range props {
+		if p.name != "EXDATE" && p.name != "RDATE" {
+			continue
+		}
+		for _, v := range strings.Split(p.value, ",") {
+			if i := strings.IndexByte(v, '/'); i >= 0 {
+				v = v[:i] // PERIOD 只取開始時間
+			}
+			t, _, floating, err := parseTime(v, p.params)
+			if err != nil {
+				return nil, &ParseError{Line: p.line, Msg: err.Error()}
+			}
+			if p.name == "EXDATE" {
+				e.ExDates = append(e.ExDates, e.frame(t, floating))
+			} else {
+				e.RDates = append(e.RDates, e.frame(t, floating))
+			}
+		}
+	}
+	return e, nil
+}
+
+// frame 把另一個屬性的時間換算到事件的參考框架：floating 事件使用牆上時間，其餘使用絕對時間
+func (e *Event) frame(t time.Time, floating bool) time.Time {
+	switch {
+	case e.Floating && !floating:
+		return wallUTC(t)
+	case !e.Floating && floating:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, e.Start.Location())
+	}
+	return t
+}
+
+// Covers 判斷 t 是否落在事件的某一次發生期間內（含開始，不含結束）
+func (e *Event) Covers(t time.Time) bool {
+	if e.Floating {
+		t = wallUTC(t)
+	}
+	length := e.End.Sub(e.Start)
+	if length <= 0 {
+		return false
+	}
+	covered := false
+	e.occurrences(t, func(start time.Time) bool {
+		if !start.After(t) && t.Before(start.Add(length)) {
+			covered = true
+			return false
+		}
+		return true
+	})
+	return covered
+}
+
+// occurrences 依序回報不晚於 limit 的每一次開始時間（DTSTART、RRULE 與 RDATE），排除 EXDATE；
+// yield 回傳 false 時停止
+func (e *Event) occurrences(limit time.Time, yield func(time.Time) bool) {
+	excluded := func(t time.Time) bool {
+		for _, x := range e.ExDates {
+			if x.Equal(t) {
+				return true
+			}
+		}
+		return false
+	}
+	emit := func(t time.Time) bool {
+		if t.After(limit) || excluded(t) {
+			return true
+		}
+		return yield(t)
+	}
+
+	if !emit(e.Start) {
+		return
+	}
+	for _, t := range e.RDates {
+		if !emit(t) {
+			return
+		}
+	}
+	if e.Rule != nil {
+		e.Rule.each(e.Start, limit, func(t time.Time) bool {
+			if t.Equal(e.Start) {
+				return true
+			}
+			return emit(t)
+		})
+	}
+}
+
+// EventAt 回傳 t 時正在進行的第一個事件
+func (c *Calendar) EventAt(t time.Time) (*Event, bool) {
+	for _, e := range c.Events {
+		if e.Covers(t) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// parseTime 解析 DATE（20260101）或 DATE-TIME（20260101T090000、結尾 Z 為 UTC、或以 TZID 指定時區）
+func parseTime(value string, params map[string]string) (t time.Time, allDay, floating bool, err error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err = time.Parse("20060102", value)
+		if err != nil {
+			return t, false, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return t, false, false, fmt.Errorf("invalid UTC date-time %q", value)
+		}
+		return t, false, false, nil
+	}
+	t, err = time.Parse("20060102T150405", value)
+	if err != nil {
+		return t, false, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	if tzid := strings.TrimPrefix(params["TZID"], "/"); tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), false, false, nil
+		}
+	}
+	return t, false, true, nil
+}
+
+// parseDuration 解析 RFC 5545 的 DURATION，例如 P1D、PT8H30M、P2W、-PT15M
+func parseDuration(value string) (time.Duration, error) {
+	s := value
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+	var d time.Duration
+	inTime := false
+	n := -1
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(r-'0')
+			continue
+		case r == 'T' && !inTime && n < 0:
+			inTime = true
+			continue
+		}
+		if n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		unit := map[rune]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+		if inTime {
+			unit = map[rune]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		}
+		u, ok := unit[r]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * u
+		n = -1
+	}
+	if n >= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return sign * d, nil
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// wallUTC 以 UTC 表示 t 的牆上時間，用於和 floating 時間比較
+func wallUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,163 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:new-year\r\n" +
+	"SUMMARY:New Year\\, observed\r\n" +
+	"DTSTART;VALUE=DATE:20250101\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Team\r\n" +
+	"  offsite\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250407T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20250407T120000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20250430T235959Z\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20250409T090000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20250414T090000\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250414T130000\r\n" +
+	"DURATION:PT1H\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20250402T000000Z\r\n" +
+	"DTEND:20250403T000000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	cal, err := Parse(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(cal.Events) != 3 {
+		t.Fatalf("Expected 3 events without the cancelled one, got %d", len(cal.Events))
+	}
+	e := cal.Events[0]
+	if e.Summary != "New Year, observed" || !e.AllDay || !e.Floating || !e.End.Equal(e.Start.AddDate(0, 0, 1)) {
+		t.Errorf("Unexpected all-day event %+v", e)
+	}
+	if cal.Events[1].Summary != "Team offsite" {
+		t.Errorf("Expected folded summary, got %q", cal.Events[1].Summary)
+	}
+	if len(cal.Events[1].ExDates) != 2 {
+		t.Errorf("Expected EXDATE and RECURRENCE-ID exclusions, got %v", cal.Events[1].ExDates)
+	}
+}
+
+func TestCalendar_EventAt(t *testing.T) {
+	cal, err := Parse(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	taipei := time.FixedZone("CST", 8*3600)
+
+	for _, tt := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2027, time.January, 1, 10, 0, 0, 0, taipei), "new-year"}, // 全天事件依當地日期
+		{time.Date(2026, time.December, 31, 23, 0, 0, 0, taipei), ""},
+		{time.Date(2025, time.April, 7, 10, 0, 0, 0, berlin), "standup"},
+		{time.Date(2025, time.April, 9, 10, 0, 0, 0, berlin), ""},          // EXDATE
+		{time.Date(2025, time.April, 14, 10, 0, 0, 0, berlin), ""},         // 被覆寫到下午
+		{time.Date(2025, time.April, 14, 13, 30, 0, 0, berlin), "standup"}, // 覆寫後的時段
+		{time.Date(2025, time.April, 16, 8, 30, 0, 0, time.UTC), "standup"},
+		{time.Date(2025, time.May, 5, 10, 0, 0, 0, berlin), ""},     // UNTIL 之後
+		{time.Date(2025, time.April, 2, 12, 0, 0, 0, time.UTC), ""}, // 已取消
+	} {
+		e, ok := cal.EventAt(tt.at)
+		got := ""
+		if ok {
+			got = e.UID
+		}
+		if got != tt.want {
+			t.Errorf("EventAt(%v) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestRule_Occurrences(t *testing.T) {
+	start := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC) // monday
+	for _, tt := range []struct {
+		rule string
+		want []string
+	}{
+		{"FREQ=DAILY;COUNT=3", []string{"2025-01-06", "2025-01-07", "2025-01-08"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=4", []string{"2025-01-06", "2025-01-10", "2025-01-20", "2025-01-24"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", []string{"2025-01-31", "2025-02-28", "2025-03-28"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3", []string{"2025-01-31", "2025-03-31", "2025-05-31"}},
+		{"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=2", []string{"2025-01-31", "2025-02-28"}},
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2", []string{"2025-11-27", "2026-11-26"}},
+		{"FREQ=YEARLY;BYMONTH=5;BYMONTHDAY=-1;UNTIL=20260601", []string{"2025-05-31", "2026-05-31"}},
+		{"FREQ=DAILY;BYMONTH=2;BYMONTHDAY=29;COUNT=1", []string{"2028-02-29"}},
+	} {
+		r, err := ParseRule(tt.rule, start, false)
+		if err != nil {
+			t.Fatalf("ParseRule(%q) failed: %v", tt.rule, err)
+		}
+		var got []string
+		r.each(start, start.AddDate(5, 0, 0), func(d time.Time) bool {
+			got = append(got, d.Format("2006-01-02"))
+			return true
+		})
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: got %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"BEGIN:VEVENT\nUID:x\nEND:VEVENT\n",                                       // 缺少 DTSTART
+		"BEGIN:VEVENT\nDTSTART:20250101T090000Z\n",                                // 缺少 END
+		"BEGIN:VEVENT\nDTSTART:2025-01-01\nEND:VEVENT\n",                          // 日期格式錯誤
+		"BEGIN:VEVENT\nDTSTART:20250101T090000Z\nRRULE:FREQ=HOURLY\nEND:VEVENT\n", // 不支援的頻率
+		"BEGIN:VEVENT\nDTSTART:20250101T090000Z\nRRULE:FREQ=DAILY;BYHOUR=9\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:20250101T090000Z\nDURATION:1H\nEND:VEVENT\n",
+	} {
+		_, err := Parse(strings.NewReader(src))
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Expected ParseError for %q, got %v", src, err)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"P1D":     24 * time.Hour,
+		"PT8H30M": 8*time.Hour + 30*time.Minute,
+		"P2W":     14 * 24 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"P1DT12H": 36 * time.Hour,
+		"+PT0S":   0,
+	} {
+		got, err := parseDuration(in)
+		if err != nil || got != want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+}
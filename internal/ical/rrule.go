@@ -0,0 +1,377 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency 為 RRULE 的 FREQ
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum 為 BYDAY 的一個值，例如 MO、1MO（第一個週一）、-1FR（最後一個週五）；N 為 0 代表每一個
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule 為解析後的 RRULE。支援 FREQ=DAILY/WEEKLY/MONTHLY/YEARLY 搭配
+// INTERVAL、COUNT、UNTIL、BYMONTH、BYMONTHDAY、BYDAY、BYSETPOS 與 WKST。
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time // 含；零值代表不限
+	ByMonth    []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// ParseRule 解析 RRULE 的值；start 為事件的 DTSTART，用來解讀 UNTIL 的參考框架
+func ParseRule(value string, start time.Time, floating bool) (*Rule, error) {
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed RRULE part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(val))
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				return nil, fmt.Errorf("unsupported RRULE FREQ %q", val)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("must be >= 1")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("must be >= 1")
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(val, start, floating)
+		case "BYMONTH":
+			r.ByMonth, err = parseInts(val, 1, 12, false)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(val, 1, 31, true)
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(val, 1, 366, true)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(val)
+		case "WKST":
+			day, ok := weekdays[strings.ToUpper(val)]
+			if !ok {
+				err = fmt.Errorf("unknown weekday")
+			}
+			r.WeekStart = day
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %s=%s: %v", key, val, err)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("RRULE %q has no FREQ", value)
+	}
+	return r, nil
+}
+
+func parseUntil(val string, start time.Time, floating bool) (time.Time, error) {
+	t, allDay, untilFloating, err := parseTime(val, nil)
+	if err != nil {
+		return t, err
+	}
+	if allDay {
+		// 日期型的 UNTIL 包含當天整天
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	switch {
+	case floating:
+		return wallUTC(t), nil
+	case untilFloating:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, start.Location()), nil
+	}
+	return t, nil
+}
+
+func parseInts(val string, lo, hi int, allowNegative bool) ([]int, error) {
+	var out []int
+	for _, s := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if allowNegative && n < 0 {
+			abs = -n
+		}
+		if abs < lo || abs > hi {
+			return nil, fmt.Errorf("%d out of range", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseByDay(val string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, s := range strings.Split(val, ",") {
+		s = strings.ToUpper(s)
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", s)
+		}
+		day, ok := weekdays[s[len(s)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", s)
+		}
+		w := WeekdayNum{Day: day}
+		if prefix := s[:len(s)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid weekday %q", s)
+			}
+			w.N = n
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// each 依時間順序回報規則產生的每一次開始時間（不早於 start），超過 limit、UNTIL 或 COUNT 即停止；
+// yield 回傳 false 時停止。COUNT 只計算規則產生的次數。
+func (r *Rule) each(start, limit time.Time, yield func(time.Time) bool) {
+	count := 0
+	for p := 0; ; p++ {
+		first, dates := r.period(start, p)
+		if first.After(limit) {
+			return
+		}
+		for _, d := range r.setPos(dates) {
+			t := time.Date(d.Year(), d.Month(), d.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+			if t.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return
+			}
+			if t.After(limit) || !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// period 回傳第 p 個週期的第一天（午夜）與其中符合規則的日期（依序）
+func (r *Rule) period(start time.Time, p int) (time.Time, []time.Time) {
+	loc := start.Location()
+	y, m, d := start.Date()
+	n := p * r.Interval
+	var dates []time.Time
+	switch r.Freq {
+	case Daily:
+		day := time.Date(y, m, d+n, 0, 0, 0, 0, loc)
+		if r.matchMonth(day) && r.matchMonthDay(day) && r.matchWeekday(day) {
+			dates = append(dates, day)
+		}
+		return day, dates
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		first := time.Date(y, m, d-offset+7*n, 0, 0, 0, 0, loc)
+		for i := 0; i < 7; i++ {
+			day := time.Date(first.Year(), first.Month(), first.Day()+i, 0, 0, 0, 0, loc)
+			if !r.matchMonth(day) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchWeekday(day) {
+				continue
+			}
+			dates = append(dates, day)
+		}
+		return first, dates
+	case Monthly:
+		first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, loc)
+		if r.matchMonth(first) {
+			dates = r.monthDays(first, start)
+		}
+		return first, dates
+	default: // Yearly
+		first := time.Date(y+n, time.January, 1, 0, 0, 0, 0, loc)
+		switch {
+		case len(r.ByMonth) > 0:
+			months := append([]int(nil), r.ByMonth...)
+			sort.Ints(months)
+			for _, mo := range months {
+				dates = append(dates, r.monthDays(time.Date(first.Year(), time.Month(mo), 1, 0, 0, 0, 0, loc), start)...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for mo := time.January; mo <= time.December; mo++ {
+				dates = append(dates, r.monthDays(time.Date(first.Year(), mo, 1, 0, 0, 0, 0, loc), start)...)
+			}
+		case len(r.ByDay) > 0:
+			dates = weekdaysIn(first, first.AddDate(1, 0, 0), r.ByDay)
+		default:
+			dates = r.monthDays(time.Date(first.Year(), m, 1, 0, 0, 0, 0, loc), start)
+		}
+		return first, dates
+	}
+}
+
+// monthDays 回傳 first 所在月份中符合 BYMONTHDAY 與 BYDAY 的日期；兩者皆未指定時為 DTSTART 的日期
+func (r *Rule) monthDays(first, start time.Time) []time.Time {
+	next := first.AddDate(0, 1, 0)
+	last := next.AddDate(0, 0, -1).Day()
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if start.Day() > last {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, start.Day()-1)}
+	}
+
+	var days []time.Time
+	if len(r.ByMonthDay) > 0 {
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md = last + md + 1
+			}
+			if md < 1 || md > last {
+				continue
+			}
+			days = append(days, first.AddDate(0, 0, md-1))
+		}
+		if len(r.ByDay) > 0 {
+			filtered := days[:0]
+			for _, d := range days {
+				if r.matchWeekday(d) {
+					filtered = append(filtered, d)
+				}
+			}
+			days = filtered
+		}
+	} else {
+		days = weekdaysIn(first, next, r.ByDay)
+	}
+	return sortDates(days)
+}
+
+// weekdaysIn 回傳 [from, to) 中符合 BYDAY 的日期；N 不為 0 時為區間內第 N 個（負數從尾端算）
+func weekdaysIn(from, to time.Time, byDay []WeekdayNum) []time.Time {
+	var days []time.Time
+	for _, w := range byDay {
+		var all []time.Time
+		for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == w.Day {
+				all = append(all, d)
+			}
+		}
+		switch {
+		case w.N == 0:
+			days = append(days, all...)
+		case w.N > 0 && w.N <= len(all):
+			days = append(days, all[w.N-1])
+		case w.N < 0 && -w.N <= len(all):
+			days = append(days, all[len(all)+w.N])
+		}
+	}
+	return sortDates(days)
+}
+
+// setPos 依 BYSETPOS 從週期內的日期中挑選（負數從尾端算）
+func (r *Rule) setPos(dates []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return dates
+	}
+	var out []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(dates) + pos
+		}
+		if i >= 0 && i < len(dates) {
+			out = append(out, dates[i])
+		}
+	}
+	return sortDates(out)
+}
+
+func (r *Rule) matchMonth(d time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == d.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchMonthDay(d time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = last + md + 1
+		}
+		if md == d.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWeekday 檢查星期是否在 BYDAY 中（忽略序號）
+func (r *Rule) matchWeekday(d time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, w := range r.ByDay {
+		if w.Day == d.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// sortDates 排序並去除重複的日期
+func sortDates(days []time.Time) []time.Time {
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	out := days[:0]
+	for i, d := range days {
+		if i == 0 || !d.Equal(days[i-1]) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package keys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Codes 記錄一個按鍵在各平台的代碼，0 代表該平台沒有對應的按鍵
+type Codes struct {
+	Linux   uint16 // linux/input-event-codes.h 的 KEY_*
+	X11     uint32 // X11 keysym
+	Windows uint16 // Windows 虛擬按鍵碼 VK_*
+	Darwin  uint16 // macOS kVK_*
+	Visible bool   // 按下後會輸入文字或觸發介面動作
+	Toggle  bool   // 鎖定鍵：每按一次切換 LED 與鎖定狀態，需再按一次還原
+}
+
+// table 為可在 actions 與巨集中使用的按鍵名稱（不分大小寫）。
+// 優先選用修飾鍵、鎖定鍵與大多數應用程式不會處理的 F13–F24。
+// macOS 沒有 ScrollLock、NumLock 與 F21–F24。
+var table = map[string]Codes{
+	"shift":      {Linux: 42, X11: 0xffe1, Windows: 0xa0, Darwin: 0x38},
+	"rightshift": {Linux: 54, X11: 0xffe2, Windows: 0xa1, Darwin: 0x3c},
+	"ctrl":       {Linux: 29, X11: 0xffe3, Windows: 0xa2, Darwin: 0x3b},
+	"alt":        {Linux: 56, X11: 0xffe9, Windows: 0xa4, Darwin: 0x3a},
+	"scrolllock": {Linux: 70, X11: 0xff14, Windows: 0x91, Toggle: true},
+	"numlock":    {Linux: 69, X11: 0xff7f, Windows: 0x90, Toggle: true},
+	"f13":        {Linux: 183, X11: 0xffca, Windows: 0x7c, Darwin: 0x69},
+	"f14":        {Linux: 184, X11: 0xffcb, Windows: 0x7d, Darwin: 0x6b},
+	"f15":        {Linux: 185, X11: 0xffcc, Windows: 0x7e, Darwin: 0x71},
+	"f16":        {Linux: 186, X11: 0xffcd, Windows: 0x7f, Darwin: 0x6a},
+	"f17":        {Linux: 187, X11: 0xffce, Windows: 0x80, Darwin: 0x40},
+	"f18":        {Linux: 188, X11: 0xffcf, Windows: 0x81, Darwin: 0x4f},
+	"f19":        {Linux: 189, X11: 0xffd0, Windows: 0x82, Darwin: 0x50},
+	"f20":        {Linux: 190, X11: 0xffd1, Windows: 0x83, Darwin: 0x5a},
+	"f21":        {Linux: 191, X11: 0xffd2, Windows: 0x84},
+	"f22":        {Linux: 192, X11: 0xffd3, Windows: 0x85},
+	"f23":        {Linux: 193, X11: 0xffd4, Windows: 0x86},
+	"f24":        {Linux: 194, X11: 0xffd5, Windows: 0x87},
+	"space":      {Linux: 57, X11: 0x0020, Windows: 0x20, Darwin: 0x31, Visible: true},
+	"enter":      {Linux: 28, X11: 0xff0d, Windows: 0x0d, Darwin: 0x24, Visible: true},
+	"tab":        {Linux: 15, X11: 0xff09, Windows: 0x09, Darwin: 0x30, Visible: true},
+	"escape":     {Linux: 1, X11: 0xff1b, Windows: 0x1b, Darwin: 0x35, Visible: true},
+	"backspace":  {Linux: 14, X11: 0xff08, Windows: 0x08, Darwin: 0x33, Visible: true},
+}
+
+// Lookup 依名稱（不分大小寫）查詢按鍵
+func Lookup(name string) (Codes, error) {
+	k, ok := table[strings.ToLower(name)]
+	if !ok {
+		return Codes{}, fmt.Errorf("unknown key name: %s", name)
+	}
+	return k, nil
+}
+
+// LookupFor 依名稱查詢按鍵，並確認 goos 平台有對應的按鍵代碼
+func LookupFor(name, goos string) (Codes, error) {
+	k, err := Lookup(name)
+	if err != nil {
+		return k, err
+	}
+	if goos == "darwin" && k.Darwin == 0 {
+		return k, fmt.Errorf("key %s is not supported on macOS", name)
+	}
+	return k, nil
+}
+
+// Taps 回傳一次動作要按幾下：鎖定鍵按兩下，讓 LED 與鎖定狀態回到原樣
+func (k Codes) Taps() int {
+	if k.Toggle {
+		return 2
+	}
+	return 1
+}
+
+// Names 回傳所有可用的按鍵名稱（已排序）
+func Names() []string {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,33 @@
+package keys
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	k, err := Lookup("ScrollLock")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if k.Linux != 70 || k.Taps() != 2 {
+		t.Errorf("Expected ScrollLock tapped twice with KEY_SCROLLLOCK, got %+v", k)
+	}
+	if k, _ := Lookup("f15"); k.Taps() != 1 || k.Visible {
+		t.Errorf("Expected a single invisible F15 tap, got %+v", k)
+	}
+	if _, err := Lookup("Hyper"); err == nil {
+		t.Error("Expected error for unknown key name, got nil")
+	}
+}
+
+func TestLookupFor_RejectsKeysMissingOnMacOS(t *testing.T) {
+	for _, name := range []string{"ScrollLock", "NumLock", "F21", "F24"} {
+		if _, err := LookupFor(name, "darwin"); err == nil {
+			t.Errorf("Expected %s to be rejected on darwin", name)
+		}
+		if _, err := LookupFor(name, "linux"); err != nil {
+			t.Errorf("Expected %s to be accepted on linux, got %v", name, err)
+		}
+	}
+	if _, err := LookupFor("F15", "darwin"); err != nil {
+		t.Errorf("Expected F15 to be accepted on darwin, got %v", err)
+	}
+}
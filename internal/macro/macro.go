@@ -0,0 +1,214 @@
+package macro
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Op 為巨集指令
+type Op string
+
+const (
+	OpPress  Op = "press"  // press <key>：按下並放開按鍵
+	OpWait   Op = "wait"   // wait <duration>：等待，例如 50ms
+	OpMove   Op = "move"   // move <dx>,<dy>：相對移動滑鼠（不會自動移回）
+	OpScroll Op = "scroll" // scroll <n>：捲動滾輪 n 格，正值向上
+	OpClick  Op = "click"  // click [left|right|middle]：點擊滑鼠
+)
+
+// Position 為指令在巨集原始碼中的位置（皆從 1 起算）
+type Position struct {
+	Line int
+	Col  int
+}
+
+// Statement 為一條已解析的巨集指令
+type Statement struct {
+	Op       Op
+	Key      string
+	Duration time.Duration
+	DX       int32
+	DY       int32
+	Amount   int32
+	Button   string
+	Pos      Position
+}
+
+// Macro 為一個具名的指令序列
+type Macro struct {
+	Name       string
+	Statements []Statement
+}
+
+// SyntaxError 描述巨集原始碼中的錯誤位置
+type SyntaxError struct {
+	Macro string
+	Pos   Position
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("macro %q: line %d, column %d: %s", e.Macro, e.Pos.Line, e.Pos.Col, e.Msg)
+}
+
+// token 為一個以空白分隔的字詞及其起始欄位
+type token struct {
+	text string
+	col  int
+}
+
+// Parse 解析巨集原始碼。指令以分號或換行分隔，# 之後到行尾為註解，例如：
+//
+//	press shift; wait 50ms; move 3,0; wait 20ms; move -3,0; scroll 0
+func Parse(name, src string) (*Macro, error) {
+	m := &Macro{Name: name}
+	for i, line := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		runes := []rune(line)
+		if idx := indexRune(runes, '#'); idx >= 0 {
+			runes = runes[:idx]
+		}
+
+		start := 0
+		for start <= len(runes) {
+			end := indexRune(runes[start:], ';')
+			if end < 0 {
+				end = len(runes)
+			} else {
+				end += start
+			}
+			toks := tokenize(runes[start:end], start+1)
+			if len(toks) > 0 {
+				st, err := parseStatement(toks)
+				if err != nil {
+					err.Macro = name
+					err.Pos.Line = lineNo
+					return nil, err
+				}
+				st.Pos = Position{Line: lineNo, Col: toks[0].col}
+				m.Statements = append(m.Statements, st)
+			}
+			start = end + 1
+		}
+	}
+	if len(m.Statements) == 0 {
+		return nil, &SyntaxError{Macro: name, Pos: Position{Line: 1, Col: 1}, Msg: "macro is empty"}
+	}
+	return m, nil
+}
+
+func parseStatement(toks []token) (Statement, *SyntaxError) {
+	op, args := toks[0], toks[1:]
+	fail := func(col int, format string, a ...interface{}) (Statement, *SyntaxError) {
+		return Statement{}, &SyntaxError{Pos: Position{Col: col}, Msg: fmt.Sprintf(format, a...)}
+	}
+	argCol := op.col + len([]rune(op.text))
+	if len(args) > 0 {
+		argCol = args[0].col
+	}
+
+	st := Statement{Op: Op(strings.ToLower(op.text))}
+	switch st.Op {
+	case OpPress:
+		if len(args) != 1 {
+			return fail(argCol, "press expects one key name")
+		}
+		st.Key = args[0].text
+
+	case OpWait:
+		if len(args) != 1 {
+			return fail(argCol, "wait expects one duration, e.g. 50ms")
+		}
+		d, err := time.ParseDuration(args[0].text)
+		if err != nil || d < 0 {
+			return fail(args[0].col, "invalid duration %q", args[0].text)
+		}
+		st.Duration = d
+
+	case OpMove:
+		// 允許 "3,0"、"3, 0" 或 "3 , 0" 等寫法
+		var joined strings.Builder
+		for _, a := range args {
+			joined.WriteString(a.text)
+		}
+		parts := strings.Split(joined.String(), ",")
+		if len(args) == 0 || len(parts) != 2 {
+			return fail(argCol, "move expects dx,dy")
+		}
+		dx, err := parseInt32(parts[0])
+		if err != nil {
+			return fail(argCol, "invalid dx %q", parts[0])
+		}
+		dy, err := parseInt32(parts[1])
+		if err != nil {
+			return fail(argCol, "invalid dy %q", parts[1])
+		}
+		st.DX, st.DY = dx, dy
+
+	case OpScroll:
+		if len(args) != 1 {
+			return fail(argCol, "scroll expects one amount")
+		}
+		n, err := parseInt32(args[0].text)
+		if err != nil {
+			return fail(args[0].col, "invalid scroll amount %q", args[0].text)
+		}
+		st.Amount = n
+
+	case OpClick:
+		switch len(args) {
+		case 0:
+			st.Button = "left"
+		case 1:
+			st.Button = strings.ToLower(args[0].text)
+			if st.Button != "left" && st.Button != "right" && st.Button != "middle" {
+				return fail(args[0].col, "unknown mouse button %q", args[0].text)
+			}
+		default:
+			return fail(args[1].col, "click expects at most one button")
+		}
+
+	default:
+		return fail(op.col, "unknown command %q; must be one of: press, wait, move, scroll, click", op.text)
+	}
+	return st, nil
+}
+
+// tokenize 以空白切分字詞，col 為 runes[0] 所在的欄位
+func tokenize(runes []rune, col int) []token {
+	var toks []token
+	start := -1
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				toks = append(toks, token{string(runes[start:i]), col + start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		toks = append(toks, token{string(runes[start:]), col + start})
+	}
+	return toks
+}
+
+func indexRune(runes []rune, r rune) int {
+	for i, c := range runes {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseInt32(s string) (int32, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	return int32(n), err
+}
@@ -0,0 +1,67 @@
+package macro
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse_Example(t *testing.T) {
+	m, err := Parse("nudge", "press shift; wait 50ms; move 3,0; wait 20ms; move -3, 0; scroll 0")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []Statement{
+		{Op: OpPress, Key: "shift", Pos: Position{1, 1}},
+		{Op: OpWait, Duration: 50 * time.Millisecond, Pos: Position{1, 14}},
+		{Op: OpMove, DX: 3, Pos: Position{1, 25}},
+		{Op: OpWait, Duration: 20 * time.Millisecond, Pos: Position{1, 35}},
+		{Op: OpMove, DX: -3, Pos: Position{1, 46}},
+		{Op: OpScroll, Pos: Position{1, 58}},
+	}
+	if !reflect.DeepEqual(m.Statements, want) {
+		t.Errorf("Expected %+v, got %+v", want, m.Statements)
+	}
+}
+
+func TestParse_MultiLineWithComments(t *testing.T) {
+	src := "# 先按 F15\npress F15\n\n  click right   # 右鍵\nwait 1s;"
+	m, err := Parse("multi", src)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(m.Statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %+v", m.Statements)
+	}
+	if st := m.Statements[1]; st.Op != OpClick || st.Button != "right" || st.Pos != (Position{4, 3}) {
+		t.Errorf("Unexpected click statement %+v", st)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := []struct {
+		src string
+		pos Position
+	}{
+		{"press shift; jump 3", Position{1, 14}},
+		{"press shift\nwait soon", Position{2, 6}},
+		{"move 3", Position{1, 6}},
+		{"move 3,x", Position{1, 6}},
+		{"press", Position{1, 6}},
+		{"scroll up", Position{1, 8}},
+		{"click left right", Position{1, 12}},
+		{"  # only a comment", Position{1, 1}},
+	}
+	for _, c := range cases {
+		_, err := Parse("bad", c.src)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: expected SyntaxError, got %v", c.src, err)
+			continue
+		}
+		if se.Pos != c.pos || se.Macro != "bad" {
+			t.Errorf("%q: expected error at %+v, got %v", c.src, c.pos, se)
+		}
+	}
+}
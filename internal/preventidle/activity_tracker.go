@@ -0,0 +1,107 @@
+package preventidle
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// injectionTolerance 為判定「最後一次輸入是我們自己送出的」時允許的時間誤差，
+// 涵蓋注入到系統閒置計數歸零之間的延遲與閒置時間的取樣精度
+const injectionTolerance = time.Second
+
+// ActivityTracker 包裝 InputInjector 與 IdleSource，記錄每次模擬輸入的時間，
+// 並據此把系統閒置時間中由我們自己造成的歸零排除，推算出真正的使用者閒置時間。
+//
+// 系統回報的閒置時間只能推得「最後一次輸入」發生的時刻；若該時刻落在某次注入之後的
+// 誤差範圍內，即視為我們自己的輸入，使用者閒置時間沿用注入前最後一次觀察到的真人輸入時刻。
+type ActivityTracker struct {
+	injector InputInjector
+	idle     IdleSource
+	now      func() time.Time
+
+	mu            sync.Mutex
+	lastInjection time.Time
+	lastHuman     time.Time
+	injections    int
+}
+
+// NewActivityTracker 建立 ActivityTracker；injector 或 idle 為 nil 時對應的操作會回傳錯誤
+func NewActivityTracker(injector InputInjector, idle IdleSource) *ActivityTracker {
+	return &ActivityTracker{injector: injector, idle: idle, now: time.Now}
+}
+
+// SendInput 實作 InputInjector：先取樣一次閒置時間以保留注入前的真人活動時刻，再送出輸入並記錄時間
+func (a *ActivityTracker) SendInput(inputType string) error {
+	return a.inject(func(injector InputInjector) error { return injector.SendInput(inputType) })
+}
+
+// Perform 實作 InputInjector，與 SendInput 相同地記錄注入時間
+func (a *ActivityTracker) Perform(action SimulateAction) error {
+	return a.inject(func(injector InputInjector) error { return injector.Perform(action) })
+}
+
+func (a *ActivityTracker) inject(send func(InputInjector) error) error {
+	if a.injector == nil {
+		return errors.New("no input injection backend available")
+	}
+	// 取樣失敗不影響注入，只是這次無法更新真人活動時刻
+	_, _ = a.UserIdleTime()
+
+	if err := send(a.injector); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.lastInjection = a.now()
+	a.injections++
+	a.mu.Unlock()
+	return nil
+}
+
+// IdleTime 實作 IdleSource，回傳系統回報的閒置時間（包含我們注入的輸入）
+func (a *ActivityTracker) IdleTime() (time.Duration, error) {
+	if a.idle == nil {
+		return 0, errors.New("no idle time backend available")
+	}
+	return a.idle.IdleTime()
+}
+
+// UserIdleTime 回傳排除我們自己注入的輸入後，真正的使用者閒置時間
+func (a *ActivityTracker) UserIdleTime() (time.Duration, error) {
+	system, err := a.IdleTime()
+	if err != nil {
+		return 0, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	lastInput := now.Add(-system)
+	ours := !a.lastInjection.IsZero() && !lastInput.After(a.lastInjection.Add(injectionTolerance))
+	if !ours && lastInput.After(a.lastHuman) {
+		a.lastHuman = lastInput
+	}
+	if a.lastHuman.IsZero() {
+		// 追蹤開始前就已注入且從未觀察到真人輸入，只能以系統值為準
+		return system, nil
+	}
+	d := now.Sub(a.lastHuman)
+	if d < 0 {
+		d = 0
+	}
+	return d, nil
+}
+
+// LastInjection 回傳最後一次成功注入輸入的時間；尚未注入時為零值
+func (a *ActivityTracker) LastInjection() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastInjection
+}
+
+// Injections 回傳目前為止成功注入的次數
+func (a *ActivityTracker) Injections() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.injections
+}
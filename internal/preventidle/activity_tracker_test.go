@@ -0,0 +1,86 @@
+package preventidle
+
+import (
+	"testing"
+	"time"
+)
+
+// clockDesktop 以可控時鐘模擬系統：任何輸入（真人或注入）都會讓閒置時間歸零
+type clockDesktop struct {
+	now       time.Time
+	lastInput time.Time
+}
+
+func (d *clockDesktop) SendInput(string) error {
+	d.lastInput = d.now
+	return nil
+}
+
+func (d *clockDesktop) Perform(SimulateAction) error { return d.SendInput("") }
+
+func (d *clockDesktop) IdleTime() (time.Duration, error) {
+	return d.now.Sub(d.lastInput), nil
+}
+
+func (d *clockDesktop) advance(dur time.Duration) { d.now = d.now.Add(dur) }
+
+func TestActivityTracker_ExcludesInjectedInput(t *testing.T) {
+	start := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local)
+	desk := &clockDesktop{now: start, lastInput: start}
+	tracker := NewActivityTracker(desk, desk)
+	tracker.now = func() time.Time { return desk.now }
+
+	desk.advance(10 * time.Minute)
+	if err := tracker.SendInput("key"); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+	desk.advance(2 * time.Minute)
+
+	if idle, _ := tracker.IdleTime(); idle != 2*time.Minute {
+		t.Errorf("Expected system idle 2m since injection, got %v", idle)
+	}
+	if idle, _ := tracker.UserIdleTime(); idle != 12*time.Minute {
+		t.Errorf("Expected user idle 12m ignoring injection, got %v", idle)
+	}
+
+	// 再注入一次，使用者閒置時間仍持續累積
+	if err := tracker.SendInput("mouse"); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+	desk.advance(time.Minute)
+	if idle, _ := tracker.UserIdleTime(); idle != 13*time.Minute {
+		t.Errorf("Expected user idle 13m after second injection, got %v", idle)
+	}
+	if tracker.Injections() != 2 || !tracker.LastInjection().Equal(start.Add(12*time.Minute)) {
+		t.Errorf("Expected 2 injections, last at 09:12, got %d at %v", tracker.Injections(), tracker.LastInjection())
+	}
+}
+
+func TestActivityTracker_HumanInputResetsUserIdle(t *testing.T) {
+	start := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.Local)
+	desk := &clockDesktop{now: start, lastInput: start}
+	tracker := NewActivityTracker(desk, desk)
+	tracker.now = func() time.Time { return desk.now }
+
+	desk.advance(10 * time.Minute)
+	tracker.SendInput("key")
+	desk.advance(time.Minute)
+
+	// 真人在注入之後操作
+	desk.lastInput = desk.now
+	desk.advance(30 * time.Second)
+
+	if idle, _ := tracker.UserIdleTime(); idle != 30*time.Second {
+		t.Errorf("Expected user idle 30s after human input, got %v", idle)
+	}
+}
+
+func TestActivityTracker_NoBackends(t *testing.T) {
+	tracker := NewActivityTracker(nil, nil)
+	if err := tracker.SendInput("key"); err == nil {
+		t.Error("Expected SendInput error without injector")
+	}
+	if _, err := tracker.UserIdleTime(); err == nil {
+		t.Error("Expected UserIdleTime error without idle source")
+	}
+}
@@ -0,0 +1,99 @@
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// interval: auto 的預設值
+const (
+	DefaultAutoFraction = 0.5
+	DefaultAutoRecheck  = 10 * time.Minute
+	DefaultAutoFallback = 5 * time.Minute
+)
+
+// TimeoutProbe 讀取一個來源的螢幕保護或電源管理逾時；回傳 0 代表該來源未啟用逾時
+type TimeoutProbe struct {
+	Name string
+	Read func() (time.Duration, error)
+}
+
+// DetectIdleTimeout 回傳所有來源中最短的有效逾時與其來源名稱。
+// 讀取失敗的來源會被記錄並略過；所有來源都未啟用逾時時回傳 0。
+func DetectIdleTimeout(probes []TimeoutProbe) (time.Duration, string, error) {
+	var best time.Duration
+	var source string
+	var failures []string
+	for _, p := range probes {
+		d, err := p.Read()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", p.Name, err))
+			continue
+		}
+		if d > 0 && (best == 0 || d < best) {
+			best, source = d, p.Name
+		}
+	}
+	if best == 0 && len(failures) == len(probes) {
+		if len(failures) == 0 {
+			return 0, "", errors.New("no screensaver timeout source on this platform")
+		}
+		return 0, "", fmt.Errorf("no screensaver timeout source available (%s)", strings.Join(failures, "; "))
+	}
+	return best, source, nil
+}
+
+// AutoInterval 依偵測到的逾時推算閒置門檻，回傳門檻與其來源說明。
+// 門檻為逾時乘上 autoInterval.fraction；偵測不到逾時時使用 fallback；
+// 門檻至少為排程間隔的兩倍，確保每個門檻週期內至少檢查兩次。
+// 若兩倍排程間隔已達到或超過逾時，門檻維持在逾時以下，並回傳說明設定衝突的錯誤，
+// 此時回傳的門檻仍可使用。
+func AutoInterval(cfg *config.APPConfig, probes []TimeoutProbe) (time.Duration, string, error) {
+	auto := cfg.IdlePrevention.AutoInterval
+	fraction := auto.Fraction
+	if fraction <= 0 {
+		fraction = DefaultAutoFraction
+	}
+	fallback := auto.Fallback
+	if fallback <= 0 {
+		fallback = DefaultAutoFallback
+	}
+
+	var interval time.Duration
+	var source string
+	timeout, name, err := DetectIdleTimeout(probes)
+	switch {
+	case err != nil:
+		interval, source = fallback, fmt.Sprintf("fallback, %v", err)
+		timeout = 0
+	case timeout == 0:
+		interval, source = fallback, "fallback, screensaver timeout disabled"
+	default:
+		interval = time.Duration(float64(timeout) * fraction)
+		source = fmt.Sprintf("%v of %s timeout %v", fraction, name, timeout)
+	}
+
+	floor := 2 * cfg.Scheduler.Interval
+	if interval >= floor {
+		return interval, source, nil
+	}
+	if timeout > 0 && floor >= timeout {
+		return interval, source, fmt.Errorf("scheduler.interval %v is too long for the %s timeout %v: "+
+			"the idle threshold %v can't be raised to twice the scheduler interval without reaching the timeout; "+
+			"set scheduler.interval below %v", cfg.Scheduler.Interval, name, timeout, interval, timeout/2)
+	}
+	source += fmt.Sprintf(", raised to twice the scheduler interval %v", cfg.Scheduler.Interval)
+	return floor, source, nil
+}
+
+// AutoIntervalRecheck 回傳重新讀取逾時的間隔
+func AutoIntervalRecheck(cfg *config.IdlePreventionConfig) time.Duration {
+	if cfg.AutoInterval.Recheck > 0 {
+		return cfg.AutoInterval.Recheck
+	}
+	return DefaultAutoRecheck
+}
@@ -0,0 +1,168 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/dpms"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// DefaultDconfDir 為系統 dconf keyfile 資料庫的預設目錄
+const DefaultDconfDir = "/etc/dconf/db"
+
+// gnomeSessionSchema、gnomeIdleDelayKey 為 GNOME 閒置逾時的設定位置
+const (
+	gnomeSessionSchema = "org.gnome.desktop.session"
+	gnomeIdleDelayKey  = "idle-delay"
+)
+
+// ScreensaverTimeoutProbes 回傳 Linux 上讀取螢幕保護逾時的來源：
+// X11 GetScreenSaver、X11 DPMS、gsettings 與 dconf keyfile
+func ScreensaverTimeoutProbes(cfg *config.IdlePreventionConfig) []TimeoutProbe {
+	dconfDir := cfg.AutoInterval.DconfDir
+	if dconfDir == "" {
+		dconfDir = DefaultDconfDir
+	}
+	display := cfg.X11.Display
+	return []TimeoutProbe{
+		{Name: "X11 screensaver", Read: func() (time.Duration, error) { return x11ScreenSaverTimeout(display) }},
+		{Name: "X11 DPMS", Read: func() (time.Duration, error) { return x11DPMSTimeout(display) }},
+		{Name: "gsettings " + gnomeSessionSchema + " " + gnomeIdleDelayKey, Read: gsettingsIdleDelay},
+		{Name: "dconf " + dconfDir, Read: func() (time.Duration, error) { return dconfIdleDelay(dconfDir) }},
+	}
+}
+
+// x11ScreenSaverTimeout 以 GetScreenSaver 讀取 X 伺服器的螢幕保護逾時
+func x11ScreenSaverTimeout(display string) (time.Duration, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return 0, fmt.Errorf("connect to X display %q failed: %w", display, err)
+	}
+	defer conn.Close()
+	reply, err := xproto.GetScreenSaver(conn).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("GetScreenSaver failed: %w", err)
+	}
+	return time.Duration(reply.Timeout) * time.Second, nil
+}
+
+// x11DPMSTimeout 讀取 DPMS 的 standby／suspend／off 逾時中最短者；DPMS 未啟用時回傳 0
+func x11DPMSTimeout(display string) (time.Duration, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return 0, fmt.Errorf("connect to X display %q failed: %w", display, err)
+	}
+	defer conn.Close()
+	if err := dpms.Init(conn); err != nil {
+		return 0, fmt.Errorf("DPMS extension unavailable: %w", err)
+	}
+	info, err := dpms.Info(conn).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("DPMS Info failed: %w", err)
+	}
+	if !info.State {
+		return 0, nil
+	}
+	timeouts, err := dpms.GetTimeouts(conn).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("DPMS GetTimeouts failed: %w", err)
+	}
+	var shortest uint16
+	for _, t := range []uint16{timeouts.StandbyTimeout, timeouts.SuspendTimeout, timeouts.OffTimeout} {
+		if t > 0 && (shortest == 0 || t < shortest) {
+			shortest = t
+		}
+	}
+	return time.Duration(shortest) * time.Second, nil
+}
+
+// gsettingsIdleDelay 以 gsettings 讀取 GNOME 的 idle-delay（秒）
+func gsettingsIdleDelay() (time.Duration, error) {
+	path, err := exec.LookPath("gsettings")
+	if err != nil {
+		return 0, errors.New("gsettings not installed")
+	}
+	out, err := exec.Command(path, "get", gnomeSessionSchema, gnomeIdleDelayKey).Output()
+	if err != nil {
+		return 0, fmt.Errorf("gsettings get %s %s failed: %w", gnomeSessionSchema, gnomeIdleDelayKey, err)
+	}
+	return parseGVariantSeconds(string(out))
+}
+
+// dconfIdleDelay 讀取 dir 下各資料庫的 keyfile（<db>.d/*）中 [org/gnome/desktop/session] 的 idle-delay。
+// 依 dconf 編譯資料庫的規則，檔名排序較後者覆蓋較前者；沒有任何設定時回傳錯誤。
+func dconfIdleDelay(dir string) (time.Duration, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.d", "*"))
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(files)
+	section := "[" + strings.ReplaceAll(gnomeSessionSchema, ".", "/") + "]"
+
+	var value string
+	for _, file := range files {
+		if v, ok, err := readKeyfileValue(file, section, gnomeIdleDelayKey); err != nil {
+			return 0, err
+		} else if ok {
+			value = v
+		}
+	}
+	if value == "" {
+		return 0, fmt.Errorf("%s not set in %s", gnomeIdleDelayKey, dir)
+	}
+	return parseGVariantSeconds(value)
+}
+
+// readKeyfileValue 從 dconf keyfile 讀取 section 中 key 的值
+func readKeyfileValue(file, section, key string) (string, bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	defer f.Close()
+
+	var current, value string
+	var found bool
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			current = line
+		case current == section:
+			if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) == key {
+				value, found = strings.TrimSpace(v), true
+			}
+		}
+	}
+	return value, found, sc.Err()
+}
+
+// parseGVariantSeconds 解析 GVariant 文字格式的秒數，例如 "uint32 300" 或 "300"
+func parseGVariantSeconds(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "uint32"))
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", gnomeIdleDelayKey, s)
+	}
+	return time.Duration(n) * time.Second, nil
+}
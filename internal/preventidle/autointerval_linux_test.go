@@ -0,0 +1,70 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestParseGVariantSeconds(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"uint32 300\n": 5 * time.Minute,
+		"600":          10 * time.Minute,
+		"uint32 0":     0,
+	} {
+		if got, err := parseGVariantSeconds(in); err != nil || got != want {
+			t.Errorf("parseGVariantSeconds(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseGVariantSeconds("@u nothing"); err == nil {
+		t.Error("Expected error for malformed value, got nil")
+	}
+}
+
+func TestDconfIdleDelay_LaterKeyfileWins(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, "local.d", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("00-screensaver", "[org/gnome/desktop/screensaver]\nlock-delay=uint32 30\n\n[org/gnome/desktop/session]\nidle-delay=uint32 900\n")
+	write("10-override", "# site policy\n[org/gnome/desktop/session]\nidle-delay = uint32 240\n")
+
+	d, err := dconfIdleDelay(dir)
+	if err != nil || d != 4*time.Minute {
+		t.Errorf("Expected 4m from the later keyfile, got %v (%v)", d, err)
+	}
+
+	if _, err := dconfIdleDelay(t.TempDir()); err == nil {
+		t.Error("Expected error when idle-delay is not set, got nil")
+	}
+}
+
+func TestX11ScreenSaverTimeout(t *testing.T) {
+	display := startXvfb(t)
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer conn.Close()
+	if err := xproto.SetScreenSaverChecked(conn, 420, 600, xproto.BlankingPreferred, xproto.ExposuresDefault).Check(); err != nil {
+		t.Fatalf("SetScreenSaver failed: %v", err)
+	}
+
+	d, err := x11ScreenSaverTimeout(display)
+	if err != nil || d != 7*time.Minute {
+		t.Errorf("Expected 7m screensaver timeout, got %v (%v)", d, err)
+	}
+}
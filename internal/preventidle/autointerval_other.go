@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package preventidle
+
+import "github.com/HanksJCTsai/goidleguard/internal/config"
+
+// ScreensaverTimeoutProbes 回傳讀取螢幕保護逾時的來源；目前只支援 Linux，其他平台使用 fallback
+func ScreensaverTimeoutProbes(cfg *config.IdlePreventionConfig) []TimeoutProbe {
+	return nil
+}
@@ -0,0 +1,75 @@
+package preventidle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func fixedProbe(name string, d time.Duration, err error) TimeoutProbe {
+	return TimeoutProbe{Name: name, Read: func() (time.Duration, error) { return d, err }}
+}
+
+func TestDetectIdleTimeout_ShortestSource(t *testing.T) {
+	d, source, err := DetectIdleTimeout([]TimeoutProbe{
+		fixedProbe("screensaver", 10*time.Minute, nil),
+		fixedProbe("dpms", 0, nil),
+		fixedProbe("gsettings", 5*time.Minute, nil),
+		fixedProbe("dconf", 0, errors.New("not set")),
+	})
+	if err != nil || d != 5*time.Minute || source != "gsettings" {
+		t.Errorf("Expected 5m from gsettings, got %v from %q (%v)", d, source, err)
+	}
+}
+
+func TestDetectIdleTimeout_AllSourcesFail(t *testing.T) {
+	if _, _, err := DetectIdleTimeout([]TimeoutProbe{fixedProbe("x11", 0, errors.New("no display"))}); err == nil ||
+		!strings.Contains(err.Error(), "no display") {
+		t.Errorf("Expected error naming the failed source, got %v", err)
+	}
+	if _, _, err := DetectIdleTimeout(nil); err == nil {
+		t.Error("Expected error without any source, got nil")
+	}
+}
+
+func TestAutoInterval(t *testing.T) {
+	cfg := &config.APPConfig{Scheduler: config.SchedulerConfig{Interval: 10 * time.Second}}
+	cfg.IdlePrevention.IntervalAuto = true
+
+	interval, source, err := AutoInterval(cfg, []TimeoutProbe{fixedProbe("gsettings", 10*time.Minute, nil)})
+	if err != nil || interval != 5*time.Minute || !strings.Contains(source, "gsettings") {
+		t.Errorf("Expected half of the 10m timeout, got %v (%s)", interval, source)
+	}
+
+	cfg.IdlePrevention.AutoInterval.Fraction = 0.8
+	if interval, _, _ := AutoInterval(cfg, []TimeoutProbe{fixedProbe("x11", 10*time.Minute, nil)}); interval != 8*time.Minute {
+		t.Errorf("Expected 8m with fraction 0.8, got %v", interval)
+	}
+
+	interval, source, _ = AutoInterval(cfg, []TimeoutProbe{fixedProbe("x11", 0, nil)})
+	if interval != DefaultAutoFallback || !strings.Contains(source, "disabled") {
+		t.Errorf("Expected fallback for disabled screensaver, got %v (%s)", interval, source)
+	}
+
+	cfg.IdlePrevention.AutoInterval.Fallback = 2 * time.Minute
+	if interval, _, _ := AutoInterval(cfg, []TimeoutProbe{fixedProbe("x11", 0, errors.New("no display"))}); interval != 2*time.Minute {
+		t.Errorf("Expected configured fallback, got %v", interval)
+	}
+
+	// 門檻不低於排程間隔的兩倍
+	if interval, _, err := AutoInterval(cfg, []TimeoutProbe{fixedProbe("x11", 24*time.Second, nil)}); err != nil || interval != 20*time.Second {
+		t.Errorf("Expected threshold raised to 20s, got %v (%v)", interval, err)
+	}
+
+	// 兩倍排程間隔已達到逾時：門檻維持在逾時以下並回報錯誤
+	interval, _, err = AutoInterval(cfg, []TimeoutProbe{fixedProbe("x11", 15*time.Second, nil)})
+	if interval != 12*time.Second {
+		t.Errorf("Expected threshold kept below the 15s timeout, got %v", interval)
+	}
+	if err == nil || !strings.Contains(err.Error(), "scheduler.interval") {
+		t.Errorf("Expected error about scheduler.interval, got %v", err)
+	}
+}
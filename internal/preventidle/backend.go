@@ -0,0 +1,166 @@
+package preventidle
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// InputInjector 負責送出模擬的鍵盤或滑鼠輸入。
+// SendInput 送出 inputType 的預設動作，Perform 送出任意設定的動作。
+type InputInjector interface {
+	SendInput(inputType string) error
+	Perform(action SimulateAction) error
+}
+
+// IdleSource 回報目前的系統閒置時間
+type IdleSource interface {
+	IdleTime() (time.Duration, error)
+}
+
+// InputKindSource 由能分辨最後一筆輸入是鍵盤或滑鼠的閒置來源實作，
+// 回傳 ActionKey、ActionMouse，無法分辨時回傳空字串
+type InputKindSource interface {
+	LastInputKind() string
+}
+
+// PowerAsserter 負責宣告與解除防止休眠的電源鎖
+type PowerAsserter interface {
+	PreventSleep() error
+	AllowIdle() error
+}
+
+// Prober 由能在啟動時檢查能力與權限的元件實作，回傳 nil 代表可用
+type Prober interface {
+	Probe() error
+}
+
+// Backend 組合一個具名後端所提供的能力，不支援的能力為 nil
+type Backend struct {
+	Name     string
+	Injector InputInjector
+	Idle     IdleSource
+	Power    PowerAsserter
+}
+
+// BackendFactory 依設定建立一個新的後端實例
+type BackendFactory func(cfg *config.IdlePreventionConfig) (*Backend, error)
+
+// Capabilities 描述後端在目前環境下會提供的能力
+type Capabilities struct {
+	Input bool
+	Idle  bool
+	Power bool
+}
+
+// CapabilityCheck 只依設定與環境（例如環境變數、裝置檔權限）推斷後端會提供的能力，
+// 不得建立輸入裝置、X 連線或 D-Bus 連線
+type CapabilityCheck func(cfg *config.IdlePreventionConfig) Capabilities
+
+var (
+	registryMu    sync.RWMutex
+	registry      = map[string]BackendFactory{}
+	idleRegistry  = map[string]BackendFactory{}
+	checkRegistry = map[string]CapabilityCheck{}
+)
+
+// RegisterBackend 以名稱註冊後端，重複註冊會覆蓋先前的 factory
+func RegisterBackend(name string, factory BackendFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// RegisterIdleSource 註冊後端只提供閒置時間的 factory，不得建立輸入裝置或電源鎖。
+// dry-run 模式只用這些 factory 讀取閒置時間。
+func RegisterIdleSource(name string, factory BackendFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	idleRegistry[name] = factory
+}
+
+// RegisterCapabilities 註冊後端的能力推斷，dry-run 以此記錄實際執行時會使用的後端
+func RegisterCapabilities(name string, check CapabilityCheck) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	checkRegistry[name] = check
+}
+
+// NewBackend 依名稱建立已註冊的後端
+func NewBackend(name string, cfg *config.IdlePreventionConfig) (*Backend, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown idle prevention backend: %s", name)
+	}
+	return newBackend(name, factory, cfg)
+}
+
+// NewIdleBackend 依名稱建立只提供閒置時間的後端
+func NewIdleBackend(name string, cfg *config.IdlePreventionConfig) (*Backend, error) {
+	registryMu.RLock()
+	factory, ok := idleRegistry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("backend %s provides no idle source", name)
+	}
+	return newBackend(name, factory, cfg)
+}
+
+func newBackend(name string, factory BackendFactory, cfg *config.IdlePreventionConfig) (*Backend, error) {
+	b, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create backend %s failed: %w", name, err)
+	}
+	if b.Name == "" {
+		b.Name = name
+	}
+	return b, nil
+}
+
+// BackendNames 回傳所有已註冊的後端名稱（已排序）
+func BackendNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Probe 逐一檢查後端的各項能力，移除檢查失敗的能力並回傳各自的錯誤。
+// 若所有能力都不可用，則回傳 false。
+func (b *Backend) Probe() (usable bool, errs []error) {
+	if b.Injector != nil {
+		if err := probe(b.Injector); err != nil {
+			errs = append(errs, fmt.Errorf("%s input: %w", b.Name, err))
+			b.Injector = nil
+		}
+	}
+	if b.Idle != nil {
+		if err := probe(b.Idle); err != nil {
+			errs = append(errs, fmt.Errorf("%s idle: %w", b.Name, err))
+			b.Idle = nil
+		}
+	}
+	if b.Power != nil {
+		if err := probe(b.Power); err != nil {
+			errs = append(errs, fmt.Errorf("%s power: %w", b.Name, err))
+			b.Power = nil
+		}
+	}
+	return b.Injector != nil || b.Idle != nil || b.Power != nil, errs
+}
+
+func probe(component interface{}) error {
+	if p, ok := component.(Prober); ok {
+		return p.Probe()
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package preventidle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestNewBackend_Registered(t *testing.T) {
+	b, err := NewBackend(FakeBackendName, &config.IdlePreventionConfig{})
+	if err != nil {
+		t.Fatalf("NewBackend failed: %v", err)
+	}
+	if b.Name != FakeBackendName || b.Injector == nil || b.Idle == nil || b.Power == nil {
+		t.Errorf("Expected fully populated fake backend, got %+v", b)
+	}
+}
+
+func TestNewBackend_Unknown(t *testing.T) {
+	if _, err := NewBackend("does-not-exist", &config.IdlePreventionConfig{}); err == nil {
+		t.Error("Expected error for unknown backend, got nil")
+	}
+}
+
+func TestSimulateActivity_FakeBackend(t *testing.T) {
+	fake := NewFakeBackend()
+	fake.SetIdle(time.Minute)
+
+	if err := SimulateActivity(fake, &config.IdlePreventionConfig{Mode: "mixed"}, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	inputs := fake.Inputs()
+	if len(inputs) != 2 || inputs[0] != "key" || inputs[1] != "mouse" {
+		t.Errorf("Expected [key mouse], got %v", inputs)
+	}
+	if idle, _ := fake.IdleTime(); idle != 0 {
+		t.Errorf("Expected idle reset to 0 after input, got %v", idle)
+	}
+}
+
+func TestSimulateActivity_PropagatesError(t *testing.T) {
+	fake := NewFakeBackend()
+	fake.InputErr = errors.New("boom")
+
+	if err := SimulateActivity(fake, &config.IdlePreventionConfig{Mode: "key"}, nil); !errors.Is(err, fake.InputErr) {
+		t.Errorf("Expected wrapped injector error, got %v", err)
+	}
+}
+
+// failingProber 為檢查失敗的注入器
+type failingProber struct{ FakeBackend }
+
+func (*failingProber) Probe() error { return errors.New("permission denied") }
+
+func TestBackendProbe_DropsFailingCapability(t *testing.T) {
+	fake := NewFakeBackend()
+	b := &Backend{Name: "partial", Injector: &failingProber{}, Idle: fake}
+
+	usable, errs := b.Probe()
+	if !usable {
+		t.Fatal("Expected backend to remain usable with idle source")
+	}
+	if len(errs) != 1 || b.Injector != nil || b.Idle == nil {
+		t.Errorf("Expected only injector dropped, got errs=%v backend=%+v", errs, b)
+	}
+}
+
+func TestChain_FallsBackAtRuntime(t *testing.T) {
+	first := NewFakeBackend()
+	second := NewFakeBackend()
+	c := NewChain(
+		&Backend{Name: "first", Injector: first},
+		&Backend{Name: "second", Injector: second, Idle: second},
+	)
+	if got := c.Active(); got.Input != "first" || got.Idle != "second" || got.Power != "" {
+		t.Fatalf("Unexpected initial selection: %+v", got)
+	}
+
+	first.InputErr = errors.New("permission revoked")
+	if err := c.SendInput("key"); err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+	if got := c.Active().Input; got != "second" {
+		t.Errorf("Expected input to fall back to second, got %s", got)
+	}
+	if inputs := second.Inputs(); len(inputs) != 1 || inputs[0] != "key" {
+		t.Errorf("Expected retried input on second backend, got %v", inputs)
+	}
+}
+
+func TestChain_RetriesFirstBackendAfterBackoff(t *testing.T) {
+	first := NewFakeBackend()
+	second := NewFakeBackend()
+	c := NewChain(
+		&Backend{Name: "first", Injector: first},
+		&Backend{Name: "second", Injector: second},
+	)
+	now := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	first.InputErr = errors.New("transient")
+	second.InputErr = errors.New("transient")
+	if err := c.SendInput("key"); err == nil {
+		t.Fatal("Expected error when every backend fails, got nil")
+	}
+	if got := c.Active().Input; got != "" {
+		t.Fatalf("Expected no input backend after failures, got %s", got)
+	}
+
+	first.InputErr, second.InputErr = nil, nil
+	now = now.Add(ChainRetryBackoff / 2)
+	if err := c.SendInput("key"); err == nil {
+		t.Error("Expected capability to stay disabled during backoff, got nil")
+	}
+	now = now.Add(ChainRetryBackoff)
+	if err := c.SendInput("key"); err != nil {
+		t.Fatalf("Expected retry after backoff to succeed, got %v", err)
+	}
+	if got := c.Active().Input; got != "first" {
+		t.Errorf("Expected chain to return to first backend, got %s", got)
+	}
+	if inputs := first.Inputs(); len(inputs) != 1 {
+		t.Errorf("Expected input on first backend, got %v", inputs)
+	}
+}
+
+func TestChain_AllBackendsFail(t *testing.T) {
+	only := NewFakeBackend()
+	only.InputErr = errors.New("boom")
+	c := NewChain(&Backend{Name: "only", Injector: only})
+
+	if err := c.SendInput("key"); !errors.Is(err, only.InputErr) {
+		t.Errorf("Expected joined backend error, got %v", err)
+	}
+	if err := c.PreventSleep(); err == nil {
+		t.Error("Expected error when no backend provides power, got nil")
+	}
+}
+
+func TestChain_AllowIdleReleasesAssertingBackend(t *testing.T) {
+	first := NewFakeBackend()
+	second := NewFakeBackend()
+	c := NewChain(
+		&Backend{Name: "first", Power: first},
+		&Backend{Name: "second", Power: second},
+	)
+	if err := c.PreventSleep(); err != nil {
+		t.Fatalf("PreventSleep failed: %v", err)
+	}
+
+	first.PowerErr = errors.New("bus disconnected")
+	if err := c.AllowIdle(); !errors.Is(err, first.PowerErr) {
+		t.Fatalf("Expected release error from asserting backend, got %v", err)
+	}
+	if second.Asserted() || c.Active().Power != "first" {
+		t.Errorf("Expected release not to fall through to second backend")
+	}
+
+	first.PowerErr = nil
+	if err := c.AllowIdle(); err != nil {
+		t.Fatalf("Expected retried release to succeed, got %v", err)
+	}
+	if first.Asserted() {
+		t.Error("Expected first backend released")
+	}
+	if err := c.AllowIdle(); err != nil {
+		t.Errorf("Expected release without assertion to be a no-op, got %v", err)
+	}
+}
+
+func TestSelectBackends_SkipsUnknown(t *testing.T) {
+	cfg := &config.IdlePreventionConfig{Backend: config.BackendList{"does-not-exist", FakeBackendName}}
+	c, err := SelectBackends(cfg)
+	if err != nil {
+		t.Fatalf("SelectBackends failed: %v", err)
+	}
+	if got := c.Active(); got.Input != FakeBackendName || got.Idle != FakeBackendName {
+		t.Errorf("Expected fake backend selected, got %+v", got)
+	}
+
+	cfg.Backend = config.BackendList{"does-not-exist"}
+	if _, err := SelectBackends(cfg); err == nil {
+		t.Error("Expected error when no backend is usable, got nil")
+	}
+}
+
+func TestPlanBackends_UsesFirstCapableBackend(t *testing.T) {
+	RegisterCapabilities("plan-input", func(*config.IdlePreventionConfig) Capabilities {
+		return Capabilities{Input: true}
+	})
+	RegisterCapabilities("plan-all", func(*config.IdlePreventionConfig) Capabilities {
+		return Capabilities{Input: true, Idle: true, Power: true}
+	})
+	cfg := &config.IdlePreventionConfig{Backend: config.BackendList{"does-not-exist", "plan-input", "plan-all"}}
+	want := ActiveBackends{Input: "plan-input", Idle: "plan-all", Power: "plan-all"}
+	if got := PlanBackends(cfg); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestSelectIdleBackends_OnlyCreatesIdleSources(t *testing.T) {
+	created := false
+	RegisterBackend("input-only", func(*config.IdlePreventionConfig) (*Backend, error) {
+		created = true
+		return &Backend{Injector: NewFakeBackend()}, nil
+	})
+	cfg := &config.IdlePreventionConfig{Backend: config.BackendList{"input-only", FakeBackendName}}
+	c, err := SelectIdleBackends(cfg)
+	if err != nil {
+		t.Fatalf("SelectIdleBackends failed: %v", err)
+	}
+	if created {
+		t.Error("Expected input-only backend not to be created")
+	}
+	if got := c.Active(); got.Input != "" || got.Idle != FakeBackendName || got.Power != "" {
+		t.Errorf("Expected only the fake idle source, got %+v", got)
+	}
+
+	cfg.Backend = config.BackendList{"input-only"}
+	if _, err := SelectIdleBackends(cfg); err == nil {
+		t.Error("Expected error when no backend provides idle time, got nil")
+	}
+}
@@ -0,0 +1,339 @@
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// AutoBackend 代表依平台預設順序自動偵測後端
+const AutoBackend = "auto"
+
+// ChainBackendName 為後端鏈對外呈現的名稱
+const ChainBackendName = "chain"
+
+// AutoBackendOrder 回傳目前平台自動偵測時嘗試的後端順序
+func AutoBackendOrder() []string {
+	switch runtime.GOOS {
+	case "linux":
+		return []string{"uinput", "xtest", "screensaver", "logind", "evdev", NativeBackendName}
+	default:
+		return []string{NativeBackendName}
+	}
+}
+
+// ActiveBackends 記錄每項能力目前使用的後端名稱，空字串代表沒有可用後端
+type ActiveBackends struct {
+	Input string `json:"input"`
+	Idle  string `json:"idle"`
+	Power string `json:"power"`
+}
+
+func (a ActiveBackends) String() string {
+	name := func(s string) string {
+		if s == "" {
+			return "none"
+		}
+		return s
+	}
+	return fmt.Sprintf("input=%s idle=%s power=%s", name(a.Input), name(a.Idle), name(a.Power))
+}
+
+// ChainRetryBackoff 為改用後備後端後，重新從第一個後端嘗試前等待的時間
+const ChainRetryBackoff = time.Minute
+
+// Chain 依序持有通過檢查的後端。每項能力使用第一個提供該能力的後端，
+// 執行期間若目前的後端失敗，會改用下一個後端並重試；經過 ChainRetryBackoff 後
+// 再從第一個後端開始嘗試，暫時性的錯誤不會讓該能力永久停用。
+// 釋放電源鎖是例外：只交給實際持有電源鎖的後端，不會改用其他後端。
+type Chain struct {
+	mu         sync.Mutex
+	candidates []*Backend
+	input      slot
+	idle       slot
+	power      slot
+	asserted   int // 目前持有電源鎖的後端索引，-1 代表沒有
+	now        func() time.Time
+}
+
+// slot 記錄一項能力目前使用的後端索引（-1 代表沒有），
+// 以及改用後備後端後何時重新從第一個後端嘗試（零值代表使用中的就是第一個）
+type slot struct {
+	active  int
+	retryAt time.Time
+}
+
+// NewChain 以已檢查過的後端建立鏈，順序即為優先順序
+func NewChain(candidates ...*Backend) *Chain {
+	c := &Chain{candidates: candidates, asserted: -1, now: time.Now}
+	c.input.active = c.next(-1, hasInjector)
+	c.idle.active = c.next(-1, hasIdle)
+	c.power.active = c.next(-1, hasPower)
+	return c
+}
+
+func hasInjector(b *Backend) bool { return b.Injector != nil }
+
+func hasIdle(b *Backend) bool { return b.Idle != nil }
+
+func hasPower(b *Backend) bool { return b.Power != nil }
+
+// SelectBackends 依 cfg.Backend 建立並檢查各後端，組成後端鏈。
+// 名稱未註冊或檢查失敗的後端會被記錄並略過；若沒有任何可用後端則回傳錯誤。
+func SelectBackends(cfg *config.IdlePreventionConfig) (*Chain, error) {
+	return selectBackends(cfg, NewBackend)
+}
+
+// SelectIdleBackends 與 SelectBackends 相同，但只建立各後端的閒置來源，
+// 不會建立輸入裝置或電源鎖，供 dry-run 使用。
+func SelectIdleBackends(cfg *config.IdlePreventionConfig) (*Chain, error) {
+	return selectBackends(cfg, NewIdleBackend)
+}
+
+// PlanBackends 依 cfg.Backend 的順序推斷每項能力實際執行時會使用的後端，不建立任何後端。
+// 沒有以 RegisterCapabilities 註冊推斷的後端視為不提供任何能力。
+func PlanBackends(cfg *config.IdlePreventionConfig) ActiveBackends {
+	var a ActiveBackends
+	for _, name := range backendOrder(cfg) {
+		registryMu.RLock()
+		check, ok := checkRegistry[name]
+		registryMu.RUnlock()
+		if !ok {
+			continue
+		}
+		caps := check(cfg)
+		if a.Input == "" && caps.Input {
+			a.Input = name
+		}
+		if a.Idle == "" && caps.Idle {
+			a.Idle = name
+		}
+		if a.Power == "" && caps.Power {
+			a.Power = name
+		}
+	}
+	return a
+}
+
+// backendOrder 回傳 cfg.Backend 指定的後端順序，未指定或為 auto 時使用平台預設順序
+func backendOrder(cfg *config.IdlePreventionConfig) []string {
+	names := cfg.Backend
+	if len(names) == 0 || (len(names) == 1 && names[0] == AutoBackend) {
+		names = AutoBackendOrder()
+	}
+	return names
+}
+
+func selectBackends(cfg *config.IdlePreventionConfig, create func(string, *config.IdlePreventionConfig) (*Backend, error)) (*Chain, error) {
+	names := backendOrder(cfg)
+
+	var candidates []*Backend
+	var failures []string
+	for _, name := range names {
+		b, err := create(name, cfg)
+		if err != nil {
+			logger.LogInfo("Backend unavailable:", err)
+			failures = append(failures, err.Error())
+			continue
+		}
+		usable, errs := b.Probe()
+		for _, err := range errs {
+			logger.LogInfo("Backend capability unavailable:", err)
+		}
+		if !usable {
+			failures = append(failures, fmt.Sprintf("%s: no usable capability", name))
+			continue
+		}
+		candidates = append(candidates, b)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no usable idle prevention backend (%s)", strings.Join(failures, "; "))
+	}
+
+	c := NewChain(candidates...)
+	logger.LogInfo("Backend selected:", c.Active())
+	return c, nil
+}
+
+// Backend 將鏈包裝成 Backend，讓呼叫端不需區分單一後端或後端鏈
+func (c *Chain) Backend() *Backend {
+	return &Backend{Name: ChainBackendName, Injector: c, Idle: c, Power: c}
+}
+
+// Active 回傳後端各項能力實際使用的後端名稱；若為後端鏈則回報目前生效的成員
+func (b *Backend) Active() ActiveBackends {
+	if c, ok := b.Injector.(*Chain); ok {
+		return c.Active()
+	}
+	var a ActiveBackends
+	if b.Injector != nil {
+		a.Input = b.Name
+	}
+	if b.Idle != nil {
+		a.Idle = b.Name
+	}
+	if b.Power != nil {
+		a.Power = b.Name
+	}
+	return a
+}
+
+// Active 回傳每項能力目前使用的後端
+func (c *Chain) Active() ActiveBackends {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ActiveBackends{
+		Input: c.nameAt(c.input.active),
+		Idle:  c.nameAt(c.idle.active),
+		Power: c.nameAt(c.power.active),
+	}
+}
+
+func (c *Chain) SendInput(inputType string) error {
+	_, err := c.do("input", &c.input, hasInjector,
+		func(b *Backend) error { return b.Injector.SendInput(inputType) })
+	return err
+}
+
+func (c *Chain) Perform(action SimulateAction) error {
+	_, err := c.do("input", &c.input, hasInjector,
+		func(b *Backend) error { return b.Injector.Perform(action) })
+	return err
+}
+
+func (c *Chain) IdleTime() (time.Duration, error) {
+	var idle time.Duration
+	_, err := c.do("idle", &c.idle, hasIdle,
+		func(b *Backend) error {
+			var err error
+			idle, err = b.Idle.IdleTime()
+			return err
+		})
+	return idle, err
+}
+
+// LastInputKind 實作 InputKindSource：目前的閒置來源能分辨輸入類型時轉交給它
+func (c *Chain) LastInputKind() string {
+	c.mu.Lock()
+	idle := c.idle.active
+	c.mu.Unlock()
+	if idle < 0 {
+		return ""
+	}
+	if k, ok := c.candidates[idle].Idle.(InputKindSource); ok {
+		return k.LastInputKind()
+	}
+	return ""
+}
+
+// PreventSleep 以目前的電源後端持有電源鎖，並記住由哪個後端持有。
+// 改由另一個後端持有時，先前的後端會被釋放，避免遺留 inhibitor。
+func (c *Chain) PreventSleep() error {
+	i, err := c.do("power", &c.power, hasPower,
+		func(b *Backend) error { return b.Power.PreventSleep() })
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	prev := c.asserted
+	c.asserted = i
+	c.mu.Unlock()
+	if prev >= 0 && prev != i {
+		if err := c.candidates[prev].Power.AllowIdle(); err != nil {
+			logger.LogError(fmt.Sprintf("Backend %s power release failed after switching to %s:", c.candidates[prev].Name, c.candidates[i].Name), err)
+		}
+	}
+	return nil
+}
+
+// AllowIdle 只在持有電源鎖的後端上釋放；釋放失敗時保留紀錄以便重試，不會改用其他後端
+func (c *Chain) AllowIdle() error {
+	c.mu.Lock()
+	i := c.asserted
+	c.mu.Unlock()
+	if i < 0 {
+		return nil
+	}
+	b := c.candidates[i]
+	if err := b.Power.AllowIdle(); err != nil {
+		return fmt.Errorf("%s: %w", b.Name, err)
+	}
+	c.mu.Lock()
+	if c.asserted == i {
+		c.asserted = -1
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// do 以目前的後端執行 call，失敗時依序改用下一個具備該能力的後端，回傳成功的後端索引。
+// 改用後備後端（或所有後端都失敗）後，經過 ChainRetryBackoff 的下一次呼叫會重新從第一個後端開始。
+// 鎖只用來讀取與更新目前的索引，呼叫後端時不持有，避免較慢的動作阻塞 Active 等查詢。
+func (c *Chain) do(capability string, s *slot, has func(*Backend) bool, call func(*Backend) error) (int, error) {
+	c.mu.Lock()
+	if !s.retryAt.IsZero() && !c.now().Before(s.retryAt) {
+		s.active, s.retryAt = c.next(-1, has), time.Time{}
+		if s.active >= 0 {
+			logger.LogInfo(fmt.Sprintf("Backend %s: retrying %s after backoff", c.candidates[s.active].Name, capability))
+		}
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for {
+		c.mu.Lock()
+		i := s.active
+		c.mu.Unlock()
+		if i < 0 {
+			break
+		}
+
+		b := c.candidates[i]
+		err := call(b)
+		if err == nil {
+			return i, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+
+		c.mu.Lock()
+		// 其他呼叫可能已經先改用下一個後端，只在索引未變時前進
+		if s.active == i {
+			s.active = c.next(i, has)
+			if s.retryAt.IsZero() {
+				s.retryAt = c.now().Add(ChainRetryBackoff)
+			}
+			if s.active >= 0 {
+				logger.LogError(fmt.Sprintf("Backend %s %s failed (%v), falling back to %s",
+					b.Name, capability, err, c.candidates[s.active].Name))
+			}
+		}
+		c.mu.Unlock()
+	}
+	if len(errs) == 0 {
+		return -1, fmt.Errorf("no backend provides %s", capability)
+	}
+	return -1, errors.Join(errs...)
+}
+
+// next 回傳 from 之後第一個符合 has 的後端索引，找不到則回傳 -1
+func (c *Chain) next(from int, has func(*Backend) bool) int {
+	for i := from + 1; i < len(c.candidates); i++ {
+		if has(c.candidates[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Chain) nameAt(i int) string {
+	if i < 0 {
+		return ""
+	}
+	return c.candidates[i].Name
+}
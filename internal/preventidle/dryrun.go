@@ -0,0 +1,169 @@
+package preventidle
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// DryRunBackendName 為 dry-run 後端對外呈現的名稱
+const DryRunBackendName = "dry-run"
+
+// DefaultDryRunJournal 為未設定 idlePrevention.dryRunJournal 時使用的日誌檔
+const DefaultDryRunJournal = "dry-run.jsonl"
+
+// 電源鎖在日誌中的動作名稱
+const (
+	JournalPreventSleep = "prevent-sleep"
+	JournalAllowIdle    = "allow-idle"
+)
+
+// JournalEntry 為 dry-run 日誌中的一筆紀錄，每筆寫成一行 JSON
+type JournalEntry struct {
+	Time    time.Time `json:"time"`
+	Mode    string    `json:"mode"`
+	Backend string    `json:"backend,omitempty"` // 實際執行時會使用的後端；沒有後端提供該能力時為空
+	Action  string    `json:"action"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// DryRun 取代真實的輸入注入與電源鎖，把每個預計的動作寫入 JSON-lines 日誌而不碰觸作業系統。
+// 閒置時間仍取自真實後端；記錄動作的時刻視為一次輸入，讓門檻判斷如同真的注入過一樣運作。
+type DryRun struct {
+	mode    string
+	backend ActiveBackends
+	idle    IdleSource
+	now     func() time.Time
+
+	mu     sync.Mutex
+	w      io.Writer
+	enc    *json.Encoder
+	reason string
+	last   time.Time
+}
+
+// NewDryRun 建立寫入 w 的 dry-run 後端；real 為偵測到的真實後端，僅用來讀取閒置時間與記錄後端名稱。
+// real 未提供的能力（例如只建立閒置來源時的輸入與電源鎖）改以 PlanBackends 推斷會使用的後端。
+// w 實作 io.Closer 時由 Close 關閉。
+func NewDryRun(w io.Writer, real *Backend, cfg *config.IdlePreventionConfig) *DryRun {
+	mode := cfg.Mode
+	if cfg.Macro != "" {
+		mode = "macro:" + cfg.Macro
+	}
+	backend := real.Active()
+	if backend.Input == "" || backend.Idle == "" || backend.Power == "" {
+		planned := PlanBackends(cfg)
+		if backend.Input == "" {
+			backend.Input = planned.Input
+		}
+		if backend.Idle == "" {
+			backend.Idle = planned.Idle
+		}
+		if backend.Power == "" {
+			backend.Power = planned.Power
+		}
+	}
+	d := &DryRun{mode: mode, backend: backend, idle: real.Idle, now: time.Now}
+	d.SetJournal(w)
+	return d
+}
+
+// SetJournal 改寫入 w，例如重新啟動時重新開啟的日誌檔；不會關閉先前的日誌
+func (d *DryRun) SetJournal(w io.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.w = w
+	d.enc = json.NewEncoder(w)
+}
+
+// Close 關閉日誌；之後記錄的動作會回傳錯誤，直到以 SetJournal 指定新的日誌
+func (d *DryRun) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w := d.w
+	d.w, d.enc = nil, nil
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Backend 將 dry-run 包裝成 Backend，三種能力皆由它提供
+func (d *DryRun) Backend() *Backend {
+	return &Backend{Name: DryRunBackendName, Injector: d, Idle: d, Power: d}
+}
+
+// SetReason 設定之後記錄的動作所附帶的原因，例如觸發模擬的閒置時間
+func (d *DryRun) SetReason(reason string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reason = reason
+}
+
+func (d *DryRun) SendInput(inputType string) error {
+	a, err := DefaultAction(inputType)
+	if err != nil {
+		return err
+	}
+	return d.Perform(a)
+}
+
+// Perform 記錄原本會送出的動作，不產生任何輸入
+func (d *DryRun) Perform(action SimulateAction) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.record(d.backend.Input, action.String()); err != nil {
+		return err
+	}
+	d.last = d.now()
+	return nil
+}
+
+// IdleTime 回傳真實閒置時間與距上次記錄動作的時間中較短者
+func (d *DryRun) IdleTime() (time.Duration, error) {
+	if d.idle == nil {
+		return 0, errors.New("no idle time backend available")
+	}
+	idle, err := d.idle.IdleTime()
+	if err != nil {
+		return 0, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.last.IsZero() {
+		if since := d.now().Sub(d.last); since < idle {
+			idle = since
+		}
+	}
+	return idle, nil
+}
+
+func (d *DryRun) PreventSleep() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.record(d.backend.Power, JournalPreventSleep)
+}
+
+func (d *DryRun) AllowIdle() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.record(d.backend.Power, JournalAllowIdle)
+}
+
+// record 寫入一筆日誌，呼叫端須持有 d.mu
+func (d *DryRun) record(backend, action string) error {
+	if d.enc == nil {
+		return errors.New("dry-run journal is closed")
+	}
+	return d.enc.Encode(JournalEntry{
+		Time:    d.now(),
+		Mode:    d.mode,
+		Backend: backend,
+		Action:  action,
+		Reason:  d.reason,
+	})
+}
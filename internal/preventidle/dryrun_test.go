@@ -0,0 +1,51 @@
+package preventidle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestDryRun_JournalsActions(t *testing.T) {
+	fake := NewFakeBackend()
+	fake.SetIdle(time.Hour)
+	var buf bytes.Buffer
+	cfg := &config.IdlePreventionConfig{Mode: "mixed"}
+	d := NewDryRun(&buf, fake.Backend(), cfg)
+	start := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return start }
+	d.SetReason("test")
+
+	if err := SimulateActivity(d, cfg, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no real input, got %v", inputs)
+	}
+
+	var actions []string
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var e JournalEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("Invalid journal line %q: %v", sc.Text(), err)
+		}
+		if !e.Time.Equal(start) || e.Mode != "mixed" || e.Backend != FakeBackendName || e.Reason != "test" {
+			t.Errorf("Unexpected journal entry %+v", e)
+		}
+		actions = append(actions, e.Action)
+	}
+	if len(actions) != 2 || actions[0] != "key press (Shift)" || actions[1] != "mouse move (1,0)" {
+		t.Errorf("Expected key and mouse entries, got %v", actions)
+	}
+
+	// 記錄動作視為一次輸入：閒置時間從記錄時刻起算
+	d.now = func() time.Time { return start.Add(2 * time.Minute) }
+	if idle, err := d.IdleTime(); err != nil || idle != 2*time.Minute {
+		t.Errorf("Expected idle 2m since journaled action, got %v (%v)", idle, err)
+	}
+}
@@ -0,0 +1,310 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// EvdevBackendName 為直接讀取 /dev/input/event* 計算閒置時間的後端名稱
+const EvdevBackendName = "evdev"
+
+const (
+	defaultEvdevDir      = "/dev/input"
+	defaultEvdevSysfsDir = "/sys/class/input"
+	evdevRescanInterval  = 5 * time.Second
+)
+
+// EvdevIdleSource 監看 /dev/input/event* 裝置，以最後一筆真實輸入事件的時間戳計算閒置時間。
+// 適用於沒有 X 伺服器或 logind 工作階段的環境（SSH 連線的 kiosk、TTY 主控台）。
+// 本程式建立的 uinput 虛擬裝置一律排除，避免把自己注入的輸入當成使用者活動。
+type EvdevIdleSource struct {
+	dir      string
+	sysfsDir string
+	include  []string
+	exclude  []string
+	now      func() time.Time
+
+	mu       sync.Mutex
+	devices  map[string]*os.File
+	names    map[string]string
+	last     time.Time
+	lastKind string
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// OpenEvdevIdleSource 依設定掃描並開始監看輸入裝置
+func OpenEvdevIdleSource(cfg config.EvdevConfig) (*EvdevIdleSource, error) {
+	e := &EvdevIdleSource{
+		dir:      cfg.Dir,
+		sysfsDir: cfg.SysfsDir,
+		include:  cfg.Include,
+		exclude:  append([]string{UinputDeviceName}, cfg.Exclude...),
+		now:      time.Now,
+		devices:  map[string]*os.File{},
+		names:    map[string]string{},
+		stop:     make(chan struct{}),
+	}
+	if e.dir == "" {
+		e.dir = defaultEvdevDir
+	}
+	if e.sysfsDir == "" {
+		e.sysfsDir = defaultEvdevSysfsDir
+	}
+	e.last = e.now()
+
+	if err := e.rescan(); err != nil {
+		return nil, err
+	}
+	e.wg.Add(1)
+	go e.rescanLoop()
+	return e, nil
+}
+
+// IdleTime 實作 IdleSource，回傳自最後一筆真實輸入事件以來的時間
+func (e *EvdevIdleSource) IdleTime() (time.Duration, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	d := e.now().Sub(e.last)
+	if d < 0 {
+		d = 0
+	}
+	return d, nil
+}
+
+// Probe 確認至少有一個裝置可以讀取（通常需要 input 群組權限）
+func (e *EvdevIdleSource) Probe() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.devices) == 0 {
+		return fmt.Errorf("no readable input devices in %s", e.dir)
+	}
+	return nil
+}
+
+// Devices 回傳目前監看中的裝置名稱（已排序）
+func (e *EvdevIdleSource) Devices() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.devices))
+	for p := range e.devices {
+		names = append(names, e.names[p])
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close 停止監看並關閉所有裝置
+func (e *EvdevIdleSource) Close() error {
+	e.mu.Lock()
+	select {
+	case <-e.stop:
+		e.mu.Unlock()
+		return nil
+	default:
+	}
+	close(e.stop)
+	for _, f := range e.devices {
+		f.Close()
+	}
+	e.mu.Unlock()
+	e.wg.Wait()
+	return nil
+}
+
+func (e *EvdevIdleSource) rescanLoop() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(evdevRescanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			if err := e.rescan(); err != nil {
+				logger.LogError("evdev: rescan failed:", err)
+			}
+		}
+	}
+}
+
+// rescan 開啟新出現且符合篩選條件的 event 裝置
+func (e *EvdevIdleSource) rescan() error {
+	paths, err := filepath.Glob(filepath.Join(e.dir, "event*"))
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range paths {
+		if _, ok := e.devices[p]; ok {
+			continue
+		}
+		name := e.deviceName(p)
+		if !e.wanted(name, filepath.Base(p)) {
+			continue
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		e.devices[p] = f
+		e.names[p] = name
+		e.wg.Add(1)
+		go e.readLoop(p, f)
+	}
+	return nil
+}
+
+// deviceName 由 sysfs 讀取裝置名稱，讀不到時以檔名代替
+func (e *EvdevIdleSource) deviceName(p string) string {
+	data, err := os.ReadFile(filepath.Join(e.sysfsDir, filepath.Base(p), "device", "name"))
+	if err != nil {
+		return filepath.Base(p)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// wanted 依 include／exclude 樣式（path.Match 語法，比對裝置名稱或檔名）決定是否監看
+func (e *EvdevIdleSource) wanted(name, base string) bool {
+	match := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, base); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if match(e.exclude) {
+		return false
+	}
+	return len(e.include) == 0 || match(e.include)
+}
+
+// readLoop 持續讀取裝置事件並更新最後活動時間；裝置移除或關閉時結束
+func (e *EvdevIdleSource) readLoop(p string, f *os.File) {
+	defer e.wg.Done()
+	size := timevalSize + 8
+	buf := make([]byte, size*64)
+	for {
+		n, err := f.Read(buf)
+		for off := 0; off+size <= n; off += size {
+			e.observe(buf[off : off+size])
+		}
+		if err != nil {
+			e.mu.Lock()
+			if e.devices[p] == f {
+				delete(e.devices, p)
+				delete(e.names, p)
+			}
+			e.mu.Unlock()
+			f.Close()
+			select {
+			case <-e.stop:
+			default:
+				if !errors.Is(err, os.ErrClosed) {
+					logger.LogInfo("evdev: stopped watching", p, err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// observe 解析一筆 input_event；同步事件以外的事件皆視為使用者活動
+func (e *EvdevIdleSource) observe(raw []byte) {
+	ts, typ := decodeInputEvent(raw)
+	if typ == evSyn {
+		return
+	}
+	code := binary.NativeEndian.Uint16(raw[timevalSize+2 : timevalSize+4])
+	e.mu.Lock()
+	if ts.IsZero() {
+		ts = e.now()
+	}
+	if ts.After(e.last) {
+		e.last = ts
+		if kind := evdevInputKind(typ, code); kind != "" {
+			e.lastKind = kind
+		}
+	}
+	e.mu.Unlock()
+}
+
+// LastInputKind 實作 InputKindSource，回傳最後一筆輸入是鍵盤或滑鼠
+func (e *EvdevIdleSource) LastInputKind() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lastKind
+}
+
+// evdevInputKind 依事件類型與代碼分類：滑鼠按鍵（BTN_*）、相對與絕對位移為滑鼠，其餘按鍵為鍵盤
+func evdevInputKind(typ, code uint16) string {
+	switch typ {
+	case evKey:
+		if code >= btnMisc {
+			return ActionMouse
+		}
+		return ActionKey
+	case evRel, evAbs:
+		return ActionMouse
+	default:
+		return ""
+	}
+}
+
+// decodeInputEvent 由 struct input_event 取出時間戳與事件類型
+func decodeInputEvent(raw []byte) (time.Time, uint16) {
+	var sec, usec int64
+	if timevalSize == 16 {
+		sec = int64(binary.NativeEndian.Uint64(raw[0:8]))
+		usec = int64(binary.NativeEndian.Uint64(raw[8:16]))
+	} else {
+		sec = int64(int32(binary.NativeEndian.Uint32(raw[0:4])))
+		usec = int64(int32(binary.NativeEndian.Uint32(raw[4:8])))
+	}
+	typ := binary.NativeEndian.Uint16(raw[timevalSize : timevalSize+2])
+	if sec == 0 && usec == 0 {
+		return time.Time{}, typ
+	}
+	return time.Unix(sec, usec*int64(time.Microsecond)), typ
+}
+
+func init() {
+	factory := func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		e, err := OpenEvdevIdleSource(cfg.Evdev)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: EvdevBackendName, Idle: e}, nil
+	}
+	RegisterBackend(EvdevBackendName, factory)
+	RegisterIdleSource(EvdevBackendName, factory)
+	// 目錄中有 event 裝置時視為提供閒置時間；只列出目錄，不開啟裝置
+	RegisterCapabilities(EvdevBackendName, func(cfg *config.IdlePreventionConfig) Capabilities {
+		dir := cfg.Evdev.Dir
+		if dir == "" {
+			dir = defaultEvdevDir
+		}
+		matches, _ := filepath.Glob(filepath.Join(dir, "event*"))
+		return Capabilities{Idle: len(matches) > 0}
+	})
+}
@@ -0,0 +1,162 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// fakeInputDevice 在暫存目錄建立 eventN fifo 與對應的 sysfs 名稱檔，並回傳寫入端
+func fakeInputDevice(t *testing.T, devDir, sysfsDir, node, name string) *os.File {
+	t.Helper()
+	p := filepath.Join(devDir, node)
+	if err := syscall.Mkfifo(p, 0600); err != nil {
+		t.Fatalf("Mkfifo failed: %v", err)
+	}
+	nameDir := filepath.Join(sysfsDir, node, "device")
+	if err := os.MkdirAll(nameDir, 0700); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(nameDir, "name"), []byte(name+"\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	// 以讀寫模式開啟，讓讀取端開啟時不會阻塞，也不會因沒有寫入端而讀到 EOF
+	w, err := os.OpenFile(p, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Open fifo failed: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+// writeTimedEvent 寫入一筆帶時間戳的 struct input_event
+func writeTimedEvent(t *testing.T, w *os.File, ts time.Time, typ, code uint16, value int32) {
+	t.Helper()
+	var buf bytes.Buffer
+	if timevalSize == 16 {
+		binary.Write(&buf, binary.NativeEndian, ts.Unix())
+		binary.Write(&buf, binary.NativeEndian, int64(ts.Nanosecond()/1000))
+	} else {
+		binary.Write(&buf, binary.NativeEndian, int32(ts.Unix()))
+		binary.Write(&buf, binary.NativeEndian, int32(ts.Nanosecond()/1000))
+	}
+	binary.Write(&buf, binary.NativeEndian, typ)
+	binary.Write(&buf, binary.NativeEndian, code)
+	binary.Write(&buf, binary.NativeEndian, value)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		t.Fatalf("Write event failed: %v", err)
+	}
+}
+
+func TestEvdevIdleSource_TracksRealDevicesOnly(t *testing.T) {
+	devDir, sysfsDir := t.TempDir(), t.TempDir()
+	keyboard := fakeInputDevice(t, devDir, sysfsDir, "event0", "AT Translated Set 2 keyboard")
+	virtual := fakeInputDevice(t, devDir, sysfsDir, "event1", UinputDeviceName)
+	fakeInputDevice(t, devDir, sysfsDir, "event2", "Power Button")
+
+	e, err := OpenEvdevIdleSource(config.EvdevConfig{
+		Dir:      devDir,
+		SysfsDir: sysfsDir,
+		Exclude:  []string{"Power*"},
+	})
+	if err != nil {
+		t.Fatalf("OpenEvdevIdleSource failed: %v", err)
+	}
+	defer e.Close()
+
+	if got := e.Devices(); len(got) != 1 || got[0] != "AT Translated Set 2 keyboard" {
+		t.Fatalf("Expected only the keyboard to be watched, got %v", got)
+	}
+	if err := e.Probe(); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	t0 := time.Date(2025, 4, 7, 9, 0, 0, 0, time.Local)
+	e.mu.Lock()
+	e.last = t0
+	e.now = func() time.Time { return t0.Add(25 * time.Second) }
+	e.mu.Unlock()
+
+	// 虛擬裝置與同步事件不應視為使用者活動
+	writeTimedEvent(t, virtual, t0.Add(20*time.Second), evKey, keyLeftShift, 1)
+	writeTimedEvent(t, keyboard, t0.Add(22*time.Second), evSyn, synReport, 0)
+	writeTimedEvent(t, keyboard, t0.Add(10*time.Second), evKey, 30, 1)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		idle, err := e.IdleTime()
+		if err != nil {
+			t.Fatalf("IdleTime failed: %v", err)
+		}
+		if idle == 15*time.Second {
+			break
+		}
+		if idle != 25*time.Second || time.Now().After(deadline) {
+			t.Fatalf("Expected idle 15s after key event, got %v", idle)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if kind := e.LastInputKind(); kind != ActionKey {
+		t.Errorf("Expected last input kind key, got %q", kind)
+	}
+}
+
+func TestEvdevIdleSource_Include(t *testing.T) {
+	devDir, sysfsDir := t.TempDir(), t.TempDir()
+	fakeInputDevice(t, devDir, sysfsDir, "event0", "AT Translated Set 2 keyboard")
+	fakeInputDevice(t, devDir, sysfsDir, "event1", "Logitech USB Optical Mouse")
+	fakeInputDevice(t, devDir, sysfsDir, "event2", "Power Button")
+
+	e, err := OpenEvdevIdleSource(config.EvdevConfig{
+		Dir:      devDir,
+		SysfsDir: sysfsDir,
+		Include:  []string{"*keyboard*", "event1"},
+	})
+	if err != nil {
+		t.Fatalf("OpenEvdevIdleSource failed: %v", err)
+	}
+	defer e.Close()
+
+	got := e.Devices()
+	if len(got) != 2 || got[0] != "AT Translated Set 2 keyboard" || got[1] != "Logitech USB Optical Mouse" {
+		t.Errorf("Expected keyboard and mouse, got %v", got)
+	}
+}
+
+func TestEvdevIdleSource_NoDevices(t *testing.T) {
+	e, err := OpenEvdevIdleSource(config.EvdevConfig{Dir: t.TempDir(), SysfsDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("OpenEvdevIdleSource failed: %v", err)
+	}
+	defer e.Close()
+
+	if err := e.Probe(); err == nil {
+		t.Error("Expected Probe to fail without readable devices")
+	}
+}
+
+func TestEvdevInputKind(t *testing.T) {
+	for _, tt := range []struct {
+		typ, code uint16
+		want      string
+	}{
+		{evKey, 30, ActionKey},
+		{evKey, btnLeft, ActionMouse},
+		{evRel, relX, ActionMouse},
+		{evAbs, 0, ActionMouse},
+		{0x04, 0, ""}, // EV_MSC
+	} {
+		if got := evdevInputKind(tt.typ, tt.code); got != tt.want {
+			t.Errorf("evdevInputKind(%#x, %#x) = %q, want %q", tt.typ, tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package preventidle
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeBackendName 為記憶體內假後端的名稱
+const FakeBackendName = "fake"
+
+// FakeBackend 為不碰觸作業系統的記憶體內後端，供測試使用。
+// 送出的輸入會被記錄下來，且如同真實系統一樣會把閒置時間歸零。
+// 它不會註冊到後端註冊表，設定檔無法選用；本套件的測試在 fake_backend_test.go 中註冊。
+type FakeBackend struct {
+	mu       sync.Mutex
+	inputs   []string
+	actions  []SimulateAction
+	moves    []Move
+	idle     time.Duration
+	asserted bool
+
+	InputErr error
+	IdleErr  error
+	PowerErr error
+}
+
+// NewFakeBackend 建立新的假後端
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{}
+}
+
+// Backend 將假後端包裝成 Backend，三種能力皆由它提供
+func (f *FakeBackend) Backend() *Backend {
+	return &Backend{Name: FakeBackendName, Injector: f, Idle: f, Power: f}
+}
+
+func (f *FakeBackend) SendInput(inputType string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.InputErr != nil {
+		return f.InputErr
+	}
+	f.inputs = append(f.inputs, inputType)
+	f.idle = 0
+	return nil
+}
+
+// Perform 記錄動作本身與滑鼠軌跡，並以動作類型記入 Inputs
+func (f *FakeBackend) Perform(action SimulateAction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.InputErr != nil {
+		return f.InputErr
+	}
+	f.inputs = append(f.inputs, action.Type)
+	f.actions = append(f.actions, action)
+	if action.Type == ActionMouse {
+		f.moves = append(f.moves, action.Trajectory()...)
+	}
+	f.idle = 0
+	return nil
+}
+
+func (f *FakeBackend) IdleTime() (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.IdleErr != nil {
+		return 0, f.IdleErr
+	}
+	return f.idle, nil
+}
+
+func (f *FakeBackend) PreventSleep() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.PowerErr != nil {
+		return f.PowerErr
+	}
+	f.asserted = true
+	return nil
+}
+
+func (f *FakeBackend) AllowIdle() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.PowerErr != nil {
+		return f.PowerErr
+	}
+	f.asserted = false
+	return nil
+}
+
+// SetIdle 設定之後 IdleTime 回報的閒置時間
+func (f *FakeBackend) SetIdle(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.idle = d
+}
+
+// Inputs 回傳目前為止送出的輸入類型
+func (f *FakeBackend) Inputs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.inputs...)
+}
+
+// Actions 回傳目前為止透過 Perform 送出的動作
+func (f *FakeBackend) Actions() []SimulateAction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SimulateAction(nil), f.actions...)
+}
+
+// Trajectory 回傳目前為止所有滑鼠動作播放的相對位移
+func (f *FakeBackend) Trajectory() []Move {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Move(nil), f.moves...)
+}
+
+// Asserted 回報目前是否持有電源鎖
+func (f *FakeBackend) Asserted() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.asserted
+}
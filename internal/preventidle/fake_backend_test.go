@@ -0,0 +1,13 @@
+package preventidle
+
+import "github.com/HanksJCTsai/goidleguard/internal/config"
+
+// 只在測試中註冊 fake 後端，正式版本的設定檔無法選用它
+func init() {
+	RegisterBackend(FakeBackendName, func(*config.IdlePreventionConfig) (*Backend, error) {
+		return NewFakeBackend().Backend(), nil
+	})
+	RegisterIdleSource(FakeBackendName, func(*config.IdlePreventionConfig) (*Backend, error) {
+		return &Backend{Name: FakeBackendName, Idle: NewFakeBackend()}, nil
+	})
+}
@@ -0,0 +1,118 @@
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path"
+	"strings"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// ErrFocusDenied 表示焦點視窗不允許模擬輸入，本次模擬已略過
+var ErrFocusDenied = errors.New("simulated input suppressed for focused window")
+
+// FocusedWindow 描述目前取得焦點的視窗
+type FocusedWindow struct {
+	Instance string // WM_CLASS 的 instance 部分
+	Class    string // WM_CLASS 的 class 部分
+	Name     string // _NET_WM_NAME，缺少時為 WM_NAME
+}
+
+func (w FocusedWindow) String() string {
+	return fmt.Sprintf("%s/%s %q", w.Instance, w.Class, w.Name)
+}
+
+// FocusSource 回報目前取得焦點的視窗
+type FocusSource interface {
+	FocusedWindow() (FocusedWindow, error)
+}
+
+// FocusGuard 在每次模擬前檢查焦點視窗，依 allow／deny 樣式決定照常模擬、略過或只移動滑鼠
+type FocusGuard struct {
+	src FocusSource
+	cfg config.FocusConfig
+}
+
+// NewFocusGuard 以焦點來源與規則建立 FocusGuard
+func NewFocusGuard(src FocusSource, cfg config.FocusConfig) *FocusGuard {
+	return &FocusGuard{src: src, cfg: cfg}
+}
+
+// FocusRulesConfigured 回報設定中是否有任何焦點視窗規則
+func FocusRulesConfigured(cfg config.FocusConfig) bool {
+	return len(cfg.Allow) > 0 || len(cfg.Deny) > 0
+}
+
+// Denied 回報視窗是否不允許模擬輸入，並說明原因：符合 deny 樣式，或設定了 allow 但都不符合
+func (g *FocusGuard) Denied(w FocusedWindow) (bool, string) {
+	if p, ok := matchWindow(g.cfg.Deny, w); ok {
+		return true, fmt.Sprintf("window %s matches deny pattern %q", w, p)
+	}
+	if len(g.cfg.Allow) > 0 {
+		if _, ok := matchWindow(g.cfg.Allow, w); !ok {
+			return true, fmt.Sprintf("window %s matches no allow pattern", w)
+		}
+	}
+	return false, ""
+}
+
+// Simulate 檢查焦點視窗後執行 SimulateActivity。焦點視窗被拒絕（或無法判斷）時，
+// onDeny 為 "mouse" 則只送出一個滑鼠動作，否則略過並回傳 ErrFocusDenied
+func (g *FocusGuard) Simulate(injector InputInjector, cfg *config.IdlePreventionConfig, rng *rand.Rand) error {
+	w, err := g.src.FocusedWindow()
+	var denied bool
+	var reason string
+	if err != nil {
+		denied, reason = true, fmt.Sprintf("focused window unknown: %v", err)
+	} else {
+		denied, reason = g.Denied(w)
+	}
+	if !denied {
+		return SimulateActivity(injector, cfg, rng)
+	}
+
+	if g.cfg.OnDeny != "mouse" {
+		return fmt.Errorf("%w (%s)", ErrFocusDenied, reason)
+	}
+	logger.LogInfo(fmt.Sprintf("Focus: %s, switching to mouse only", reason))
+	if rng == nil {
+		rng = NewRand(cfg.Jitter.Seed)
+	}
+	return PerformActions(injector, cfg, []SimulateAction{configuredAction(cfg, ActionMouse)}, rng)
+}
+
+// configuredAction 回傳設定中第一個 actionType 類型的動作，沒有時使用該類型的預設動作
+func configuredAction(cfg *config.IdlePreventionConfig, actionType string) SimulateAction {
+	if actions, err := ActionsFor(cfg); err == nil {
+		for _, a := range actions {
+			if a.Type == actionType {
+				return a
+			}
+		}
+	}
+	a, _ := DefaultAction(actionType)
+	return a
+}
+
+// matchWindow 回傳第一個符合視窗 instance、class 或標題的樣式（不分大小寫）
+func matchWindow(patterns []string, w FocusedWindow) (string, bool) {
+	for _, p := range patterns {
+		for _, field := range []string{w.Instance, w.Class, w.Name} {
+			if field != "" && globMatch(p, field) {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// slashReplacer 讓視窗標題中的 / 不影響 path.Match 的 *（例如 "vim ~/src/main.go"）
+var slashReplacer = strings.NewReplacer("/", "\x00")
+
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(slashReplacer.Replace(strings.ToLower(pattern)), slashReplacer.Replace(strings.ToLower(s)))
+	return ok
+}
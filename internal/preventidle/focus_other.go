@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package preventidle
+
+import "errors"
+
+// OpenFocusSource 開啟目前平台的焦點視窗來源；目前只支援 X11
+func OpenFocusSource(display string) (FocusSource, error) {
+	return nil, errors.New("focused window detection is only supported on X11")
+}
@@ -0,0 +1,95 @@
+package preventidle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// staticFocus 為固定回報同一個視窗的焦點來源
+type staticFocus struct {
+	window FocusedWindow
+	err    error
+}
+
+func (s staticFocus) FocusedWindow() (FocusedWindow, error) { return s.window, s.err }
+
+var terminal = FocusedWindow{Instance: "gnome-terminal-server", Class: "Gnome-terminal", Name: "user@host: ~/src/goidleguard"}
+
+func TestFocusGuard_Denied(t *testing.T) {
+	editor := FocusedWindow{Instance: "code", Class: "Code", Name: "main.go - goidleguard"}
+	tests := []struct {
+		name   string
+		cfg    config.FocusConfig
+		window FocusedWindow
+		denied bool
+	}{
+		{"deny by class", config.FocusConfig{Deny: []string{"*terminal*"}}, terminal, true},
+		{"deny is case-insensitive", config.FocusConfig{Deny: []string{"GNOME-TERMINAL"}}, terminal, true},
+		{"deny title with slash", config.FocusConfig{Deny: []string{"*~/src/*"}}, terminal, true},
+		{"deny does not match", config.FocusConfig{Deny: []string{"zoom"}}, editor, false},
+		{"allow matches", config.FocusConfig{Allow: []string{"code"}}, editor, false},
+		{"allow misses", config.FocusConfig{Allow: []string{"code"}}, terminal, true},
+		{"deny wins over allow", config.FocusConfig{Allow: []string{"*"}, Deny: []string{"*terminal*"}}, terminal, true},
+		{"no focused window with allow list", config.FocusConfig{Allow: []string{"code"}}, FocusedWindow{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			denied, reason := NewFocusGuard(nil, tt.cfg).Denied(tt.window)
+			if denied != tt.denied {
+				t.Errorf("Expected denied=%v for %s, got %v (%s)", tt.denied, tt.window, denied, reason)
+			}
+		})
+	}
+}
+
+func TestFocusGuard_SkipsDeniedWindow(t *testing.T) {
+	fake := NewFakeBackend()
+	g := NewFocusGuard(staticFocus{window: terminal}, config.FocusConfig{Deny: []string{"*terminal*"}})
+
+	err := g.Simulate(fake, &config.IdlePreventionConfig{Mode: "mixed"}, nil)
+	if !errors.Is(err, ErrFocusDenied) {
+		t.Fatalf("Expected ErrFocusDenied, got %v", err)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no input for denied window, got %v", inputs)
+	}
+}
+
+func TestFocusGuard_MouseOnlyForDeniedWindow(t *testing.T) {
+	fake := NewFakeBackend()
+	g := NewFocusGuard(staticFocus{window: terminal}, config.FocusConfig{Deny: []string{"*terminal*"}, OnDeny: "mouse"})
+	cfg := &config.IdlePreventionConfig{
+		Actions: []config.ActionConfig{{Type: "key", Key: "F15"}, {Type: "mouse", Pattern: PatternCircle}},
+	}
+
+	if err := g.Simulate(fake, cfg, nil); err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	actions := fake.Actions()
+	if len(actions) != 1 || actions[0].Type != ActionMouse || actions[0].Pattern != PatternCircle {
+		t.Errorf("Expected only the configured mouse pattern, got %v", actions)
+	}
+}
+
+func TestFocusGuard_UnknownFocusIsDenied(t *testing.T) {
+	fake := NewFakeBackend()
+	g := NewFocusGuard(staticFocus{err: errors.New("no display")}, config.FocusConfig{Deny: []string{"zoom"}})
+
+	if err := g.Simulate(fake, &config.IdlePreventionConfig{Mode: "key"}, nil); !errors.Is(err, ErrFocusDenied) {
+		t.Errorf("Expected ErrFocusDenied when focus is unknown, got %v", err)
+	}
+}
+
+func TestFocusGuard_AllowedWindowSimulates(t *testing.T) {
+	fake := NewFakeBackend()
+	g := NewFocusGuard(staticFocus{window: terminal}, config.FocusConfig{Deny: []string{"zoom"}})
+
+	if err := g.Simulate(fake, &config.IdlePreventionConfig{Mode: "mixed"}, nil); err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 2 {
+		t.Errorf("Expected [key mouse], got %v", inputs)
+	}
+}
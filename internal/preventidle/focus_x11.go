@@ -0,0 +1,151 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// X11Focus 透過 EWMH 的 _NET_ACTIVE_WINDOW 讀取焦點視窗，
+// 視窗管理員未提供時改用 GetInputFocus，並往上層視窗尋找 WM_CLASS
+type X11Focus struct {
+	mu    sync.Mutex
+	conn  *xgb.Conn
+	root  xproto.Window
+	atoms map[string]xproto.Atom
+}
+
+// OpenX11Focus 連線至指定的 X display，空字串代表使用 $DISPLAY
+func OpenX11Focus(display string) (*X11Focus, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("connect to X display %q failed: %w", display, err)
+	}
+	return &X11Focus{
+		conn:  conn,
+		root:  xproto.Setup(conn).DefaultScreen(conn).Root,
+		atoms: map[string]xproto.Atom{},
+	}, nil
+}
+
+// OpenFocusSource 開啟目前平台的焦點視窗來源
+func OpenFocusSource(display string) (FocusSource, error) {
+	return OpenX11Focus(display)
+}
+
+// FocusedWindow 實作 FocusSource
+func (f *X11Focus) FocusedWindow() (FocusedWindow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return FocusedWindow{}, errors.New("X11 focus connection is closed")
+	}
+
+	win, err := f.activeWindow()
+	if err != nil {
+		return FocusedWindow{}, err
+	}
+	if win == xproto.WindowNone || win == f.root {
+		return FocusedWindow{}, nil
+	}
+
+	// GetInputFocus 可能回傳客戶端視窗的子視窗，往上找到設定了 WM_CLASS 的視窗
+	for win != xproto.WindowNone && win != f.root {
+		class, err := f.property(win, "WM_CLASS")
+		if err != nil {
+			return FocusedWindow{}, err
+		}
+		if len(class) > 0 {
+			parts := strings.Split(strings.TrimRight(string(class), "\x00"), "\x00")
+			w := FocusedWindow{Instance: parts[0]}
+			if len(parts) > 1 {
+				w.Class = parts[1]
+			}
+			if w.Name, err = f.windowName(win); err != nil {
+				return FocusedWindow{}, err
+			}
+			return w, nil
+		}
+		tree, err := xproto.QueryTree(f.conn, win).Reply()
+		if err != nil {
+			return FocusedWindow{}, fmt.Errorf("QueryTree failed: %w", err)
+		}
+		win = tree.Parent
+	}
+	return FocusedWindow{}, nil
+}
+
+// activeWindow 讀取根視窗的 _NET_ACTIVE_WINDOW，未設定時使用輸入焦點
+func (f *X11Focus) activeWindow() (xproto.Window, error) {
+	active, err := f.property(f.root, "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return 0, err
+	}
+	if len(active) >= 4 {
+		if win := xproto.Window(xgb.Get32(active)); win != xproto.WindowNone {
+			return win, nil
+		}
+	}
+	focus, err := xproto.GetInputFocus(f.conn).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("GetInputFocus failed: %w", err)
+	}
+	return focus.Focus, nil
+}
+
+// windowName 讀取 _NET_WM_NAME（UTF-8），缺少時使用 WM_NAME
+func (f *X11Focus) windowName(win xproto.Window) (string, error) {
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		value, err := f.property(win, name)
+		if err != nil {
+			return "", err
+		}
+		if len(value) > 0 {
+			return string(value), nil
+		}
+	}
+	return "", nil
+}
+
+// property 讀取視窗屬性的原始內容，屬性不存在時回傳 nil
+func (f *X11Focus) property(win xproto.Window, name string) ([]byte, error) {
+	atom, err := f.atom(name)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := xproto.GetProperty(f.conn, false, win, atom, xproto.GetPropertyTypeAny, 0, 1024).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("GetProperty %s failed: %w", name, err)
+	}
+	return reply.Value, nil
+}
+
+// atom 回傳名稱對應的 atom，結果會快取
+func (f *X11Focus) atom(name string) (xproto.Atom, error) {
+	if a, ok := f.atoms[name]; ok {
+		return a, nil
+	}
+	reply, err := xproto.InternAtom(f.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("InternAtom %s failed: %w", name, err)
+	}
+	f.atoms[name] = reply.Atom
+	return reply.Atom, nil
+}
+
+// Close 關閉 X 連線
+func (f *X11Focus) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+}
@@ -0,0 +1,108 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"testing"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// createDummyWindow 在 display 上建立並顯示一個帶有 WM_CLASS 與 _NET_WM_NAME 的視窗
+func createDummyWindow(t *testing.T, conn *xgb.Conn, class, name string) xproto.Window {
+	t.Helper()
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatalf("NewWindowId failed: %v", err)
+	}
+	if err := xproto.CreateWindowChecked(conn, screen.RootDepth, win, screen.Root, 0, 0, 100, 100, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual, 0, nil).Check(); err != nil {
+		t.Fatalf("CreateWindow failed: %v", err)
+	}
+	setProperty(t, conn, win, "WM_CLASS", "STRING", 8, []byte(class))
+	setProperty(t, conn, win, "_NET_WM_NAME", "UTF8_STRING", 8, []byte(name))
+	if err := xproto.MapWindowChecked(conn, win).Check(); err != nil {
+		t.Fatalf("MapWindow failed: %v", err)
+	}
+	return win
+}
+
+func setProperty(t *testing.T, conn *xgb.Conn, win xproto.Window, name, typ string, format byte, data []byte) {
+	t.Helper()
+	intern := func(s string) xproto.Atom {
+		reply, err := xproto.InternAtom(conn, false, uint16(len(s)), s).Reply()
+		if err != nil {
+			t.Fatalf("InternAtom %s failed: %v", s, err)
+		}
+		return reply.Atom
+	}
+	n := uint32(len(data)) / uint32(format/8)
+	if err := xproto.ChangePropertyChecked(conn, xproto.PropModeReplace, win, intern(name), intern(typ), format, n, data).Check(); err != nil {
+		t.Fatalf("ChangeProperty %s failed: %v", name, err)
+	}
+}
+
+func TestX11Focus_ReadsActiveWindow(t *testing.T) {
+	display := startXvfb(t)
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	f, err := OpenX11Focus(display)
+	if err != nil {
+		t.Fatalf("OpenX11Focus failed: %v", err)
+	}
+	defer f.Close()
+
+	// 沒有視窗管理員也沒有焦點視窗時回報空視窗
+	if w, err := f.FocusedWindow(); err != nil || w != (FocusedWindow{}) {
+		t.Fatalf("Expected no focused window, got %+v (%v)", w, err)
+	}
+
+	// Xvfb 沒有視窗管理員，由測試代為設定 _NET_ACTIVE_WINDOW
+	term := createDummyWindow(t, conn, "xterm\x00XTerm\x00", "user@host: ~/src")
+	active := make([]byte, 4)
+	xgb.Put32(active, uint32(term))
+	setProperty(t, conn, xproto.Setup(conn).DefaultScreen(conn).Root, "_NET_ACTIVE_WINDOW", "WINDOW", 32, active)
+
+	w, err := f.FocusedWindow()
+	if err != nil {
+		t.Fatalf("FocusedWindow failed: %v", err)
+	}
+	if w.Instance != "xterm" || w.Class != "XTerm" || w.Name != "user@host: ~/src" {
+		t.Errorf("Unexpected focused window %+v", w)
+	}
+}
+
+func TestX11Focus_FallsBackToInputFocus(t *testing.T) {
+	display := startXvfb(t)
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer conn.Close()
+
+	f, err := OpenX11Focus(display)
+	if err != nil {
+		t.Fatalf("OpenX11Focus failed: %v", err)
+	}
+	defer f.Close()
+
+	zoom := createDummyWindow(t, conn, "zoom\x00zoom\x00", "Zoom Meeting")
+	if err := xproto.SetInputFocusChecked(conn, xproto.InputFocusParent, zoom, xproto.TimeCurrentTime).Check(); err != nil {
+		t.Fatalf("SetInputFocus failed: %v", err)
+	}
+
+	w, err := f.FocusedWindow()
+	if err != nil {
+		t.Fatalf("FocusedWindow failed: %v", err)
+	}
+	if w.Class != "zoom" || w.Name != "Zoom Meeting" {
+		t.Errorf("Unexpected focused window %+v", w)
+	}
+}
@@ -1,37 +1,213 @@
 package preventidle
 
 import (
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"runtime"
 	"strings"
+	"time"
 
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/keys"
 	"github.com/HanksJCTsai/goidleguard/pkg/logger"
 )
 
-func SimulateActivity(mode string) error {
+// ModeAssert 為只在工作時段持有電源鎖、不送出任何模擬輸入的模式
+const ModeAssert = "assert"
 
-	var actions []SimulateAction
-	switch strings.ToUpper(mode) {
+// 動作類型
+const (
+	ActionKey    = "key"
+	ActionMouse  = "mouse"
+	ActionClick  = "click"
+	ActionScroll = "scroll"
+)
+
+// ErrVisibleAction 表示動作會產生可見文字或點擊，但設定未允許
+var ErrVisibleAction = errors.New("action would produce visible text or clicks; set idlePrevention.allowVisible to permit it")
+
+// DefaultAction 回傳 inputType（"key" 或 "mouse"）的預設動作：
+// 按一下 Shift，或向右移動 1 像素後移回原位，兩者都不會輸入文字或點擊
+func DefaultAction(inputType string) (SimulateAction, error) {
+	switch inputType {
+	case ActionKey:
+		return SimulateAction{Type: ActionKey, Key: "Shift"}, nil
+	case ActionMouse:
+		return SimulateAction{Type: ActionMouse, DX: 1}, nil
+	default:
+		return SimulateAction{}, fmt.Errorf("unsupported input type: %s", inputType)
+	}
+}
+
+// ActionsFor 依設定組出要送出的動作：有自訂 actions 時使用之，否則依 mode 註冊的動作類型使用預設動作
+func ActionsFor(cfg *config.IdlePreventionConfig) ([]SimulateAction, error) {
+	if len(cfg.Actions) > 0 {
+		actions := make([]SimulateAction, 0, len(cfg.Actions))
+		for i, a := range cfg.Actions {
+			action := SimulateAction{
+				Type:      a.Type,
+				Key:       a.Key,
+				DX:        a.DX,
+				DY:        a.DY,
+				Button:    a.Button,
+				Pattern:   a.Pattern,
+				Radius:    a.Radius,
+				Steps:     a.Steps,
+				StepDelay: a.StepDelay,
+			}
+			if err := action.Validate(); err != nil {
+				return nil, fmt.Errorf("idlePrevention.actions[%d]: %w", i, err)
+			}
+			actions = append(actions, action)
+		}
+		return actions, nil
+	}
+
+	m, err := lookupMode(cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]SimulateAction, 0, len(m.Actions))
+	for _, t := range m.Actions {
+		a, _ := DefaultAction(t)
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// Validate 檢查動作的類型、按鍵名稱與必要欄位
+func (a SimulateAction) Validate() error {
+	switch a.Type {
+	case ActionKey:
+		_, err := keys.LookupFor(a.Key, runtime.GOOS)
+		return err
+	case ActionMouse:
+		if a.Pattern != "" {
+			if _, ok := mousePatterns[a.Pattern]; !ok {
+				return fmt.Errorf("unknown mouse pattern: %s; must be one of: %s", a.Pattern, strings.Join(PatternNames(), ", "))
+			}
+			return nil
+		}
+		if a.DX == 0 && a.DY == 0 && len(a.Moves) == 0 {
+			return errors.New("mouse action requires a non-zero dx or dy")
+		}
+		return nil
+	case ActionClick:
+		_, err := a.button()
+		return err
+	case ActionScroll:
+		return nil
+	default:
+		return fmt.Errorf("unknown action type: %s", a.Type)
+	}
+}
+
+// Visible 回報動作是否會產生可見文字或點擊
+func (a SimulateAction) Visible() bool {
+	switch a.Type {
+	case ActionClick:
+		return true
+	case ActionKey:
+		k, err := keys.Lookup(a.Key)
+		return err != nil || k.Visible
+	default:
+		return false
+	}
+}
+
+func (a SimulateAction) String() string {
+	switch a.Type {
+	case ActionKey:
+		return "key press (" + a.Key + ")"
+	case ActionMouse:
+		if a.Pattern != "" {
+			return "mouse " + a.Pattern
+		}
+		return fmt.Sprintf("mouse move (%d,%d)", a.DX, a.DY)
+	case ActionClick:
+		b, _ := a.button()
+		return b + " click"
+	case ActionScroll:
+		return fmt.Sprintf("scroll (%d)", a.Scroll)
 	default:
-	case "MIXED":
-		actions = []SimulateAction{
-			{"key", "key press"},
-			{"mouse", "mouse move"},
+		return a.Type
+	}
+}
+
+// button 回傳點擊的按鍵名稱，未指定時為 "left"
+func (a SimulateAction) button() (string, error) {
+	switch a.Button {
+	case "", "left":
+		return "left", nil
+	case "right", "middle":
+		return a.Button, nil
+	default:
+		return "", fmt.Errorf("unknown mouse button: %s", a.Button)
+	}
+}
+
+// SimulateActivity 依設定組合輸入動作，展開滑鼠移動樣式後透過 injector 依序送出。
+// 設定 macro 時執行該巨集；有自訂 actions 時送出之；否則交給 mode 註冊的執行方式。
+// rng 為 nil 時使用隨機種子。
+func SimulateActivity(injector InputInjector, cfg *config.IdlePreventionConfig, rng *rand.Rand) error {
+	if cfg.Macro != "" {
+		m, err := CompileMacro(cfg, cfg.Macro)
+		if err != nil {
+			return err
+		}
+		return RunMacro(injector, m, cfg.AllowVisible)
+	}
+
+	if rng == nil {
+		rng = NewRand(cfg.Jitter.Seed)
+	}
+	if len(cfg.Actions) == 0 {
+		m, err := lookupMode(cfg.Mode)
+		if err != nil {
+			return err
 		}
-	case "KEY":
-		actions = []SimulateAction{
-			{"key", "key press"},
+		if m.Execute != nil {
+			return m.Execute(injector, cfg, rng)
 		}
-	case "MOUSE":
-		actions = []SimulateAction{
-			{"mouse", "mouse move"},
+	}
+
+	actions, err := ActionsFor(cfg)
+	if err != nil {
+		return err
+	}
+	return PerformActions(injector, cfg, actions, rng)
+}
+
+// PerformActions 送出一組動作。若動作會產生可見文字或點擊而設定未允許（allowVisible），
+// 則拒絕整組動作、不送出任何輸入。設定 jitter 時依權重只選一種動作，並在動作之間隨機延遲。
+func PerformActions(injector InputInjector, cfg *config.IdlePreventionConfig, actions []SimulateAction, rng *rand.Rand) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	if !cfg.AllowVisible {
+		for _, a := range actions {
+			if a.Visible() {
+				return fmt.Errorf("refuse to simulate %s: %w", a, ErrVisibleAction)
+			}
 		}
 	}
 
-	for _, a := range actions {
-		if err := CallSendInput(a.inputType); err != nil {
-			return fmt.Errorf("simulate %s failed: %w", a.actionName, err)
+	actions = pickWeighted(actions, cfg.Jitter.Weights, rng)
+	for i, a := range actions {
+		if i > 0 {
+			if d := jitterDelay(cfg.Jitter, rng); d > 0 {
+				time.Sleep(d)
+			}
+		}
+		a, err := resolvePattern(a, rng)
+		if err != nil {
+			return err
+		}
+		if err := injector.Perform(a); err != nil {
+			return fmt.Errorf("simulate %s failed: %w", a, err)
 		}
-		logger.LogInfo("Simulated %s", a.actionName)
+		logger.LogInfo(fmt.Sprintf("Simulated %s", a))
 	}
 	logger.LogInfo("Simulated combined activity")
 	return nil
@@ -0,0 +1,76 @@
+package preventidle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestSimulateActivity_ConfiguredActions(t *testing.T) {
+	fake := NewFakeBackend()
+	cfg := &config.IdlePreventionConfig{
+		Mode: "key",
+		Actions: []config.ActionConfig{
+			{Type: "key", Key: "F15"},
+			{Type: "mouse", DX: 2, DY: -1},
+		},
+	}
+
+	if err := SimulateActivity(fake, cfg, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	got := fake.Actions()
+	want := []SimulateAction{{Type: ActionKey, Key: "F15"}, {Type: ActionMouse, DX: 2, DY: -1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSimulateActivity_RefusesVisibleActions(t *testing.T) {
+	for _, a := range []config.ActionConfig{
+		{Type: "key", Key: "Space"},
+		{Type: "click"},
+	} {
+		fake := NewFakeBackend()
+		cfg := &config.IdlePreventionConfig{
+			Actions: []config.ActionConfig{{Type: "key", Key: "Shift"}, a},
+		}
+		if err := SimulateActivity(fake, cfg, nil); !errors.Is(err, ErrVisibleAction) {
+			t.Errorf("Expected ErrVisibleAction for %+v, got %v", a, err)
+		}
+		if inputs := fake.Inputs(); len(inputs) != 0 {
+			t.Errorf("Expected no input sent for refused set, got %v", inputs)
+		}
+
+		cfg.AllowVisible = true
+		if err := SimulateActivity(fake, cfg, nil); err != nil {
+			t.Errorf("Expected %+v allowed with allowVisible, got %v", a, err)
+		}
+	}
+}
+
+func TestActionsFor_Defaults(t *testing.T) {
+	actions, err := ActionsFor(&config.IdlePreventionConfig{Mode: "mixed"})
+	if err != nil {
+		t.Fatalf("ActionsFor failed: %v", err)
+	}
+	if len(actions) != 2 || actions[0].Type != ActionKey || actions[1].Type != ActionMouse {
+		t.Fatalf("Expected key and mouse defaults, got %v", actions)
+	}
+	for _, a := range actions {
+		if a.Visible() {
+			t.Errorf("Expected default action %s to be invisible", a)
+		}
+	}
+}
+
+func TestActionsFor_UnknownKey(t *testing.T) {
+	_, err := ActionsFor(&config.IdlePreventionConfig{
+		Actions: []config.ActionConfig{{Type: "key", Key: "Hyper"}},
+	})
+	if err == nil {
+		t.Error("Expected error for unknown key name, got nil")
+	}
+}
@@ -0,0 +1,74 @@
+package preventidle
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// lockedSource 讓同一個亂數來源可同時被排程器與工作 goroutine 使用
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// NewRand 建立可安全並行使用的亂數產生器。seed 為 0 時使用隨機種子；
+// 指定 seed 時同樣的設定會產生完全相同的間隔、動作選擇與延遲，方便測試重現
+func NewRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return rand.New(&lockedSource{src: rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)})
+}
+
+// JitterInterval 將 base 隨機調整 ±percent%，percent 為 0 時原樣回傳
+func JitterInterval(base time.Duration, percent float64, rng *rand.Rand) time.Duration {
+	if percent <= 0 || base <= 0 {
+		return base
+	}
+	factor := 1 + (rng.Float64()*2-1)*percent/100
+	return time.Duration(float64(base) * factor)
+}
+
+// jitterDelay 在 [MinDelay, MaxDelay] 之間取一個事件間的隨機延遲
+func jitterDelay(j config.JitterConfig, rng *rand.Rand) time.Duration {
+	if j.MaxDelay <= j.MinDelay {
+		return j.MinDelay
+	}
+	return j.MinDelay + time.Duration(rng.Int64N(int64(j.MaxDelay-j.MinDelay)+1))
+}
+
+// pickWeighted 依動作類型的權重從 actions 中選出一個；未設定權重或只有一個動作時原樣回傳
+func pickWeighted(actions []SimulateAction, weights map[string]float64, rng *rand.Rand) []SimulateAction {
+	if len(weights) == 0 || len(actions) < 2 {
+		return actions
+	}
+	var total float64
+	for _, a := range actions {
+		total += weights[a.Type]
+	}
+	if total <= 0 {
+		return actions
+	}
+	r := rng.Float64() * total
+	var last SimulateAction
+	for _, a := range actions {
+		w := weights[a.Type]
+		if w <= 0 {
+			continue
+		}
+		last = a
+		if r -= w; r < 0 {
+			break
+		}
+	}
+	return []SimulateAction{last}
+}
@@ -0,0 +1,87 @@
+package preventidle
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestJitterInterval_WithinRange(t *testing.T) {
+	rng := NewRand(7)
+	base := 10 * time.Minute
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 200; i++ {
+		d := JitterInterval(base, 20, rng)
+		if d < 8*time.Minute || d > 12*time.Minute {
+			t.Fatalf("Expected interval within ±20%% of %v, got %v", base, d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("Expected varied intervals, got %d distinct values", len(seen))
+	}
+	if d := JitterInterval(base, 0, rng); d != base {
+		t.Errorf("Expected no jitter at 0%%, got %v", d)
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	rng := NewRand(1)
+	actions := []SimulateAction{{Type: ActionKey, Key: "Shift"}, {Type: ActionMouse, DX: 1}}
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		picked := pickWeighted(actions, map[string]float64{"key": 1, "mouse": 3}, rng)
+		if len(picked) != 1 {
+			t.Fatalf("Expected one action picked, got %v", picked)
+		}
+		counts[picked[0].Type]++
+	}
+	if counts["mouse"] < 650 || counts["mouse"] > 850 {
+		t.Errorf("Expected about 75%% mouse picks, got %v", counts)
+	}
+
+	for i := 0; i < 50; i++ {
+		if picked := pickWeighted(actions, map[string]float64{"key": 0, "mouse": 1}, rng); picked[0].Type != ActionMouse {
+			t.Fatalf("Expected zero-weight key never picked, got %v", picked)
+		}
+	}
+	if picked := pickWeighted(actions, nil, rng); len(picked) != 2 {
+		t.Errorf("Expected all actions without weights, got %v", picked)
+	}
+}
+
+func TestSimulateActivity_SeedReproducesRun(t *testing.T) {
+	cfg := &config.IdlePreventionConfig{
+		Actions: []config.ActionConfig{
+			{Type: "key", Key: "F15"},
+			{Type: "mouse", Pattern: PatternRandomWalk, Radius: 6, Steps: 10},
+		},
+		Jitter: config.JitterConfig{
+			Weights:  map[string]float64{"key": 1, "mouse": 1},
+			MaxDelay: time.Millisecond,
+			Seed:     42,
+		},
+	}
+	run := func() ([]string, []Move) {
+		fake := NewFakeBackend()
+		rng := NewRand(cfg.Jitter.Seed)
+		for i := 0; i < 20; i++ {
+			if err := SimulateActivity(fake, cfg, rng); err != nil {
+				t.Fatalf("SimulateActivity failed: %v", err)
+			}
+		}
+		return fake.Inputs(), fake.Trajectory()
+	}
+
+	inputs1, moves1 := run()
+	inputs2, moves2 := run()
+	if !reflect.DeepEqual(inputs1, inputs2) || !reflect.DeepEqual(moves1, moves2) {
+		t.Error("Expected identical runs with the same seed")
+	}
+	if len(inputs1) != 20 {
+		t.Errorf("Expected one weighted pick per activation, got %d inputs", len(inputs1))
+	}
+}
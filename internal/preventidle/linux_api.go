@@ -0,0 +1,179 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+var (
+	inhibitMu   sync.Mutex
+	inhibitCmd  *exec.Cmd
+	inhibitDone chan struct{} // inhibitCmd 結束並回收後關閉
+
+	uinputMu  sync.Mutex
+	uinputDev *UinputDevice
+)
+
+// inhibitArgs 為持有抑制鎖的指令，測試時可替換
+var inhibitArgs = []string{
+	"systemd-inhibit",
+	"--what=idle:sleep",
+	"--who=GoIdleGuard",
+	"--why=GoIdleGuard active",
+	"--mode=block",
+	"sleep", "infinity",
+}
+
+// PreventSleep 透過 systemd-inhibit 取得 idle:sleep 抑制鎖，直到呼叫 AllowIdle 為止。
+// 子行程提前結束時（例如沒有 polkit 權限或 logind）會被回收，下次呼叫重新取得抑制鎖。
+func PreventSleep() error {
+	inhibitMu.Lock()
+	defer inhibitMu.Unlock()
+	if inhibitCmd != nil {
+		return nil
+	}
+
+	cmd := exec.Command(inhibitArgs[0], inhibitArgs[1:]...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("systemd-inhibit failed: %w", err)
+	}
+	done := make(chan struct{})
+	inhibitCmd, inhibitDone = cmd, done
+	go func() {
+		err := cmd.Wait()
+		close(done)
+		inhibitMu.Lock()
+		defer inhibitMu.Unlock()
+		if inhibitCmd == cmd {
+			inhibitCmd, inhibitDone = nil, nil
+			logger.LogError("Linux: systemd-inhibit exited, power assertion lost:", err)
+		}
+	}()
+	logger.LogInfo("Linux: PreventSleep asserted")
+	return nil
+}
+
+// AllowIdle 結束 systemd-inhibit 子行程以釋放抑制鎖
+func AllowIdle() error {
+	inhibitMu.Lock()
+	defer inhibitMu.Unlock()
+	if inhibitCmd == nil {
+		return nil
+	}
+
+	cmd, done := inhibitCmd, inhibitDone
+	inhibitCmd, inhibitDone = nil, nil
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("release systemd-inhibit failed: %w", err)
+	}
+	<-done
+	logger.LogInfo("Linux: AllowSleep restored")
+	return nil
+}
+
+// CallSendInput 透過 UinputDevicePath 的虛擬裝置送出核心輸入事件，X11、Wayland 與純文字終端皆適用。
+// 當 mode 為 "key" 時，按下並放開 Shift 鍵；
+// 當 mode 為 "mouse" 時，向右平移 1 像素後再移回原位。
+func CallSendInput(mode string) error {
+	dev, err := sharedUinputDevice()
+	if err != nil {
+		return err
+	}
+
+	if err := dev.SendInput(mode); err != nil {
+		return err
+	}
+	logger.LogInfo("Linux: simulated " + mode + " input via uinput")
+	return nil
+}
+
+// CallPerform 透過共用的 uinput 虛擬裝置送出設定的動作
+func CallPerform(action SimulateAction) error {
+	dev, err := sharedUinputDevice()
+	if err != nil {
+		return err
+	}
+	return dev.Perform(action)
+}
+
+// sharedUinputDevice 依 UinputDevicePath 開啟（或重用）共用的虛擬裝置
+func sharedUinputDevice() (*UinputDevice, error) {
+	uinputMu.Lock()
+	defer uinputMu.Unlock()
+	if uinputDev != nil && uinputDev.Path == UinputDevicePath {
+		return uinputDev, nil
+	}
+	if uinputDev != nil {
+		_ = uinputDev.Close()
+		uinputDev = nil
+	}
+	dev, err := OpenUinputDevice(UinputDevicePath)
+	if err != nil {
+		return nil, err
+	}
+	uinputDev = dev
+	return dev, nil
+}
+
+// GetIdleTime 使用 xprintidle 取得 X 工作階段的閒置時間（以毫秒計），並轉換為 time.Duration。
+func GetIdleTime() (time.Duration, error) {
+	out, err := exec.Command("xprintidle").Output()
+	if err != nil {
+		return 0, fmt.Errorf("xprintidle failed: %w", err)
+	}
+	idleMs, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid xprintidle output (%s): %w", strings.TrimSpace(string(out)), err)
+	}
+	return time.Duration(idleMs) * time.Millisecond, nil
+}
+
+// probeNativeInput 確認 uinput 裝置可以開啟（需要寫入權限）。
+// 只開啟後立即關閉檔案，不建立虛擬裝置；共用裝置已建立時直接檢查它。
+func probeNativeInput() error {
+	uinputMu.Lock()
+	dev := uinputDev
+	uinputMu.Unlock()
+	if dev != nil && dev.Path == UinputDevicePath {
+		return dev.Probe()
+	}
+	f, err := os.OpenFile(UinputDevicePath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// checkNativeInput 以 access(2) 確認 uinput 裝置可寫入，不開啟裝置，供 dry-run 推斷後端
+func checkNativeInput() error {
+	if !uinputWritable(UinputDevicePath) {
+		return fmt.Errorf("%s is not writable", UinputDevicePath)
+	}
+	return nil
+}
+
+// probeNativeIdle 確認 xprintidle 可用且有 X 顯示環境
+func probeNativeIdle() error {
+	if os.Getenv("DISPLAY") == "" {
+		return errors.New("DISPLAY is not set")
+	}
+	_, err := exec.LookPath("xprintidle")
+	return err
+}
+
+// probeNativePower 確認 systemd-inhibit 可用
+func probeNativePower() error {
+	_, err := exec.LookPath("systemd-inhibit")
+	return err
+}
@@ -0,0 +1,53 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreventSleep_ReacquiresAfterInhibitorExits(t *testing.T) {
+	orig := inhibitArgs
+	defer func() { inhibitArgs = orig }()
+
+	// 模擬沒有權限而立即結束的 systemd-inhibit
+	inhibitArgs = []string{"sh", "-c", "exit 1"}
+	if err := PreventSleep(); err != nil {
+		t.Fatalf("PreventSleep failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		inhibitMu.Lock()
+		held := inhibitCmd != nil
+		inhibitMu.Unlock()
+		if !held {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected exited inhibitor to be reaped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	inhibitArgs = []string{"sleep", "60"}
+	if err := PreventSleep(); err != nil {
+		t.Fatalf("PreventSleep failed: %v", err)
+	}
+	inhibitMu.Lock()
+	held := inhibitCmd != nil
+	inhibitMu.Unlock()
+	if !held {
+		t.Fatal("Expected a new inhibitor after the previous one exited")
+	}
+	if err := AllowIdle(); err != nil {
+		t.Fatalf("AllowIdle failed: %v", err)
+	}
+	inhibitMu.Lock()
+	held = inhibitCmd != nil
+	inhibitMu.Unlock()
+	if held {
+		t.Error("Expected inhibitor released")
+	}
+}
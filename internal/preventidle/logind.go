@@ -0,0 +1,212 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// LogindBackendName 為透過 systemd-logind D-Bus API 的後端名稱
+const LogindBackendName = "logind"
+
+const (
+	login1Service       = "org.freedesktop.login1"
+	login1Path          = dbus.ObjectPath("/org/freedesktop/login1")
+	login1Manager       = "org.freedesktop.login1.Manager"
+	login1Session       = "org.freedesktop.login1.Session"
+	dbusPeerPing        = "org.freedesktop.DBus.Peer.Ping"
+	logindInhibitWhat   = "idle:sleep"
+	logindInhibitWho    = "GoIdleGuard"
+	logindInhibitWhy    = "GoIdleGuard active"
+	logindInhibitAction = "block"
+	systemBusSocket     = "/run/dbus/system_bus_socket"
+)
+
+// Logind 以 org.freedesktop.login1.Manager.Inhibit 取得 idle:sleep 抑制鎖，
+// 不需注入任何輸入即可讓系統保持清醒。鎖的生命週期等同於持有的檔案描述元。
+type Logind struct {
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	manager dbus.BusObject
+	lock    *os.File
+}
+
+// OpenLogind 連線至 logind 所在的匯流排，busAddress 為空時使用系統匯流排
+func OpenLogind(busAddress string) (*Logind, error) {
+	var conn *dbus.Conn
+	var err error
+	if busAddress == "" {
+		conn, err = dbus.ConnectSystemBus()
+	} else {
+		conn, err = dbus.Connect(busAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to logind bus failed: %w", err)
+	}
+	return &Logind{conn: conn, manager: conn.Object(login1Service, login1Path)}, nil
+}
+
+// PreventSleep 取得 idle:sleep 抑制鎖；已持有時不重複取得
+func (l *Logind) PreventSleep() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lock != nil {
+		return nil
+	}
+
+	var fd dbus.UnixFD
+	err := l.manager.Call(login1Manager+".Inhibit", 0,
+		logindInhibitWhat, logindInhibitWho, logindInhibitWhy, logindInhibitAction).Store(&fd)
+	if err != nil {
+		return fmt.Errorf("logind Inhibit failed: %w", err)
+	}
+	l.lock = os.NewFile(uintptr(fd), "logind-inhibit")
+	logger.LogInfo("Linux: logind inhibitor lock taken (" + logindInhibitWhat + ")")
+	return nil
+}
+
+// AllowIdle 關閉抑制鎖的檔案描述元以釋放鎖
+func (l *Logind) AllowIdle() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lock == nil {
+		return nil
+	}
+
+	err := l.lock.Close()
+	l.lock = nil
+	if err != nil {
+		return fmt.Errorf("release logind inhibitor lock failed: %w", err)
+	}
+	logger.LogInfo("Linux: logind inhibitor lock released")
+	return nil
+}
+
+// Probe 確認 logind 服務可以回應
+func (l *Logind) Probe() error {
+	if err := l.manager.Call(dbusPeerPing, 0).Err; err != nil {
+		return fmt.Errorf("logind not reachable: %w", err)
+	}
+	return nil
+}
+
+// Session 解析 logind 工作階段並回傳其 IdleSource。
+// id 為空時依序使用 $XDG_SESSION_ID 或目前行程所屬的工作階段。
+func (l *Logind) Session(id string) (*LogindSession, error) {
+	if id == "" {
+		id = os.Getenv("XDG_SESSION_ID")
+	}
+
+	var path dbus.ObjectPath
+	var err error
+	if id != "" {
+		err = l.manager.Call(login1Manager+".GetSession", 0, id).Store(&path)
+	} else {
+		err = l.manager.Call(login1Manager+".GetSessionByPID", 0, uint32(os.Getpid())).Store(&path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve logind session failed: %w", err)
+	}
+	return &LogindSession{obj: l.conn.Object(login1Service, path), now: time.Now}, nil
+}
+
+// Close 釋放抑制鎖並關閉匯流排連線
+func (l *Logind) Close() error {
+	err := l.AllowIdle()
+	if cerr := l.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LogindSession 讀取工作階段的 IdleHint／IdleSinceHint 作為 IdleSource
+type LogindSession struct {
+	obj dbus.BusObject
+	now func() time.Time
+}
+
+// IdleTime 在 IdleHint 為真時回傳自 IdleSinceHint 起經過的時間，否則視為使用者活動中
+func (s *LogindSession) IdleTime() (time.Duration, error) {
+	hint, err := s.obj.GetProperty(login1Session + ".IdleHint")
+	if err != nil {
+		return 0, fmt.Errorf("read logind IdleHint failed: %w", err)
+	}
+	idle, ok := hint.Value().(bool)
+	if !ok {
+		return 0, fmt.Errorf("unexpected logind IdleHint type %s", hint.Signature())
+	}
+	if !idle {
+		return 0, nil
+	}
+
+	since, err := s.obj.GetProperty(login1Session + ".IdleSinceHint")
+	if err != nil {
+		return 0, fmt.Errorf("read logind IdleSinceHint failed: %w", err)
+	}
+	usec, ok := since.Value().(uint64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected logind IdleSinceHint type %s", since.Signature())
+	}
+	if usec == 0 {
+		return 0, errors.New("logind IdleSinceHint not set")
+	}
+	d := s.now().Sub(time.UnixMicro(int64(usec)))
+	if d < 0 {
+		d = 0
+	}
+	return d, nil
+}
+
+// Probe 確認工作階段物件可以讀取
+func (s *LogindSession) Probe() error {
+	_, err := s.obj.GetProperty(login1Session + ".IdleHint")
+	return err
+}
+
+func init() {
+	RegisterBackend(LogindBackendName, func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		l, err := OpenLogind(cfg.Logind.BusAddress)
+		if err != nil {
+			return nil, err
+		}
+		b := &Backend{Name: LogindBackendName, Power: l}
+		session, err := l.Session(cfg.Logind.Session)
+		if err != nil {
+			logger.LogInfo("Backend capability unavailable: logind idle:", err)
+		} else {
+			b.Idle = session
+		}
+		return b, nil
+	})
+	RegisterIdleSource(LogindBackendName, func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		l, err := OpenLogind(cfg.Logind.BusAddress)
+		if err != nil {
+			return nil, err
+		}
+		session, err := l.Session(cfg.Logind.Session)
+		if err != nil {
+			l.conn.Close()
+			return nil, err
+		}
+		return &Backend{Name: LogindBackendName, Idle: session}, nil
+	})
+	// 系統匯流排可連線時視為提供電源鎖與閒置時間；工作階段是否存在要連線後才能確認
+	RegisterCapabilities(LogindBackendName, func(cfg *config.IdlePreventionConfig) Capabilities {
+		hasBus := cfg.Logind.BusAddress != "" || os.Getenv("DBUS_SYSTEM_BUS_ADDRESS") != ""
+		if !hasBus {
+			_, err := os.Stat(systemBusSocket)
+			hasBus = err == nil
+		}
+		return Capabilities{Idle: hasBus, Power: hasBus}
+	})
+}
@@ -0,0 +1,234 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+const privateBusConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startDBus 啟動一個私有的 dbus-daemon 並回傳其位址；未安裝 dbus-daemon 時略過測試
+func startDBus(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bus")
+	conf := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(conf, []byte(fmt.Sprintf(privateBusConfig, socket)), 0600); err != nil {
+		t.Fatalf("Failed to write bus config: %v", err)
+	}
+
+	cmd := exec.Command(path, "--config-file="+conf, "--nofork", "--nopidfile")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if c, err := net.Dial("unix", socket); err == nil {
+			c.Close()
+			return "unix:path=" + socket
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for dbus-daemon")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// connectService 以新連線匯出假的 D-Bus 服務，並在匯出完成後才取得服務名稱，
+// 避免客戶端在物件就緒前收到擁有者變更
+func connectService(t *testing.T, addr, name string, export func(conn *dbus.Conn) error) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatalf("Failed to connect service: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := export(conn); err != nil {
+		t.Fatalf("Failed to export %s: %v", name, err)
+	}
+	reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: reply=%v err=%v", name, reply, err)
+	}
+	return conn
+}
+
+// fakeLogin1 模擬 org.freedesktop.login1.Manager 的 Inhibit 與 GetSession
+type fakeLogin1 struct {
+	mu       sync.Mutex
+	inhibits [][]string
+	readEnds []*os.File
+	keep     []*os.File
+}
+
+func (f *fakeLogin1) Inhibit(what, who, why, mode string) (dbus.UnixFD, *dbus.Error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return 0, dbus.MakeFailedError(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inhibits = append(f.inhibits, []string{what, who, why, mode})
+	f.readEnds = append(f.readEnds, r)
+	f.keep = append(f.keep, w)
+	return dbus.UnixFD(w.Fd()), nil
+}
+
+func (f *fakeLogin1) GetSession(id string) (dbus.ObjectPath, *dbus.Error) {
+	if id != "1" {
+		return "", dbus.NewError("org.freedesktop.login1.NoSuchSession", []interface{}{"no session " + id})
+	}
+	return "/org/freedesktop/login1/session/_31", nil
+}
+
+// releaseServerCopies 關閉服務端持有的寫入端，之後管線只剩客戶端持有的鎖
+func (f *fakeLogin1) releaseServerCopies() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, w := range f.keep {
+		w.Close()
+	}
+	f.keep = nil
+}
+
+// lockHeld 檢查最近一次的抑制鎖是否仍被持有（管線尚未 EOF）
+func (f *fakeLogin1) lockHeld(t *testing.T) bool {
+	t.Helper()
+	f.mu.Lock()
+	r := f.readEnds[len(f.readEnds)-1]
+	f.mu.Unlock()
+
+	r.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err := r.Read(make([]byte, 1))
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+func startFakeLogind(t *testing.T, addr string, idleHint bool, idleSince time.Time) *fakeLogin1 {
+	t.Helper()
+	fake := &fakeLogin1{}
+	connectService(t, addr, login1Service, func(conn *dbus.Conn) error {
+		if err := conn.Export(fake, login1Path, login1Manager); err != nil {
+			return err
+		}
+		_, err := prop.Export(conn, "/org/freedesktop/login1/session/_31", prop.Map{
+			login1Session: {
+				"IdleHint":      {Value: idleHint},
+				"IdleSinceHint": {Value: uint64(idleSince.UnixMicro())},
+			},
+		})
+		return err
+	})
+	return fake
+}
+
+func TestLogind_InhibitorLock(t *testing.T) {
+	addr := startDBus(t)
+	fake := startFakeLogind(t, addr, false, time.Time{})
+
+	l, err := OpenLogind(addr)
+	if err != nil {
+		t.Fatalf("OpenLogind failed: %v", err)
+	}
+	defer l.Close()
+
+	if err := l.Probe(); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if err := l.PreventSleep(); err != nil {
+		t.Fatalf("PreventSleep failed: %v", err)
+	}
+	fake.releaseServerCopies()
+
+	if got := fake.inhibits; len(got) != 1 || got[0][0] != "idle:sleep" || got[0][3] != "block" {
+		t.Fatalf("Expected one idle:sleep block inhibit, got %v", got)
+	}
+	if !fake.lockHeld(t) {
+		t.Fatal("Expected inhibitor lock to be held after PreventSleep")
+	}
+
+	if err := l.AllowIdle(); err != nil {
+		t.Fatalf("AllowIdle failed: %v", err)
+	}
+	if fake.lockHeld(t) {
+		t.Error("Expected inhibitor lock to be released after AllowIdle")
+	}
+}
+
+func TestLogindSession_IdleHint(t *testing.T) {
+	addr := startDBus(t)
+	since := time.Now().Add(-90 * time.Second)
+	startFakeLogind(t, addr, true, since)
+
+	b, err := NewBackend(LogindBackendName, &config.IdlePreventionConfig{
+		Logind: config.LogindConfig{BusAddress: addr, Session: "1"},
+	})
+	if err != nil {
+		t.Fatalf("NewBackend failed: %v", err)
+	}
+	if usable, errs := b.Probe(); !usable || len(errs) != 0 {
+		t.Fatalf("Expected logind backend usable, got errs=%v", errs)
+	}
+	if b.Injector != nil {
+		t.Error("Expected logind backend to provide no input injection")
+	}
+
+	idle, err := b.Idle.IdleTime()
+	if err != nil {
+		t.Fatalf("IdleTime failed: %v", err)
+	}
+	if idle < 89*time.Second || idle > 100*time.Second {
+		t.Errorf("Expected idle around 90s, got %v", idle)
+	}
+}
+
+func TestLogind_UnknownSession(t *testing.T) {
+	addr := startDBus(t)
+	startFakeLogind(t, addr, false, time.Time{})
+
+	l, err := OpenLogind(addr)
+	if err != nil {
+		t.Fatalf("OpenLogind failed: %v", err)
+	}
+	defer l.Close()
+
+	if _, err := l.Session("42"); err == nil {
+		t.Error("Expected error for unknown session, got nil")
+	}
+}
@@ -11,6 +11,11 @@ package preventidle
 #cgo LDFLAGS: -framework IOKit -framework CoreFoundation
 #include <IOKit/pwr_mgt/IOPMLib.h>
 #include <CoreFoundation/CoreFoundation.h>
+
+// CGEventCreateScrollWheelEvent 為可變參數函式，cgo 無法直接呼叫
+static CGEventRef createScrollWheelEvent(CGEventSourceRef source, int32_t lines) {
+	return CGEventCreateScrollWheelEvent(source, kCGScrollEventUnitLine, 1, lines);
+}
 */
 import "C"
 import (
@@ -19,6 +24,7 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/HanksJCTsai/goidleguard/internal/keys"
 	"github.com/HanksJCTsai/goidleguard/pkg/logger"
 )
 
@@ -71,24 +77,40 @@ func AllowIdle() error {
 }
 
 // CallSendInput 使用 Core Graphics API 模擬鍵盤或滑鼠事件。
-// 當 mode 為 "key" 時，以 Shift 鍵 (key code 56) 為示例產生鍵盤按下與釋放事件；
-// 當 mode 為 "mouse" 時，取得目前滑鼠位置，並向右平移 1 像素後建立滑鼠移動事件。
+// 當 mode 為 "key" 時，按下並放開 Shift 鍵；
+// 當 mode 為 "mouse" 時，向右平移 1 像素後再移回原位，不會點擊。
 func CallSendInput(mode string) error {
-	switch mode {
-	case "key":
-		source := C.CGEventSourceCreate(C.kCGEventSourceStateCombinedSessionState)
-		if source == (C.CGEventSourceRef)(unsafe.Pointer(nil)) {
-			return errors.New("failed to create event source for keyboard")
-		}
-		defer C.CFRelease(C.CFTypeRef(source))
+	action, err := DefaultAction(mode)
+	if err != nil {
+		return errors.New("unsupported mode for CallSendInput on macOS")
+	}
+	return CallPerform(action)
+}
+
+// CallPerform 使用 Core Graphics API 送出設定的按鍵、滑鼠相對位移軌跡或點擊
+func CallPerform(action SimulateAction) error {
+	source := C.CGEventSourceCreate(C.kCGEventSourceStateCombinedSessionState)
+	if source == (C.CGEventSourceRef)(unsafe.Pointer(nil)) {
+		return errors.New("failed to create event source")
+	}
+	defer C.CFRelease(C.CFTypeRef(source))
 
-		var key C.CGKeyCode = 56 // 空格鍵 (kVK_Space)
+	switch action.Type {
+	case ActionKey:
+		k, err := keys.Lookup(action.Key)
+		if err != nil {
+			return err
+		}
+		if k.Darwin == 0 {
+			return fmt.Errorf("key %s is not supported on macOS", action.Key)
+		}
+		key := C.CGKeyCode(k.Darwin)
 
 		eventDown := C.CGEventCreateKeyboardEvent(source, key, C.bool(true))
 		if eventDown == (C.CGEventRef)(unsafe.Pointer(nil)) {
 			return errors.New("failed to create key down event")
 		}
-		C.CGEventPost(C.kCGSessionEventTap, eventDown) // 改用 kCGSessionEventTap
+		C.CGEventPost(C.kCGSessionEventTap, eventDown)
 		C.CFRelease(C.CFTypeRef(eventDown))
 
 		C.usleep(10000)
@@ -99,55 +121,85 @@ func CallSendInput(mode string) error {
 		}
 		C.CGEventPost(C.kCGSessionEventTap, eventUp)
 		C.CFRelease(C.CFTypeRef(eventUp))
-		logger.LogInfo("macOS: simulated key press (space key)")
+		logger.LogInfo("macOS: simulated key press (" + action.Key + ")")
 		return nil
-	case "mouse":
-		source := C.CGEventSourceCreate(C.kCGEventSourceStateCombinedSessionState)
-		if source == (C.CGEventSourceRef)(unsafe.Pointer(nil)) {
-			return errors.New("failed to create event source for mouse")
-		}
-		defer C.CFRelease(C.CFTypeRef(source))
 
-		event := C.CGEventCreate(source)
-		if event == (C.CGEventRef)(unsafe.Pointer(nil)) {
-			return errors.New("failed to create event for mouse location")
+	case ActionMouse:
+		// Core Graphics 只接受絕對座標，每一步都以目前位置加上相對位移
+		err := playMoves(action, func(dx, dy int32) error {
+			location, err := cursorLocation(source)
+			if err != nil {
+				return err
+			}
+			moved := C.CGPointMake(location.x+C.CGFloat(dx), location.y+C.CGFloat(dy))
+			return postMouseEvent(source, C.kCGEventMouseMoved, moved, C.kCGMouseButtonLeft)
+		})
+		if err != nil {
+			return err
 		}
-		location := C.CGEventGetLocation(event)
-		C.CFRelease(C.CFTypeRef(event))
-
-		newX := location.x + 1.0
-		newY := location.y
-		newPoint := C.CGPointMake(newX, newY)
+		logger.LogInfo("macOS: simulated mouse move")
+		return nil
 
-		mouseEvent := C.CGEventCreateMouseEvent(source, C.kCGEventMouseMoved, newPoint, C.kCGMouseButtonLeft)
-		if mouseEvent == (C.CGEventRef)(unsafe.Pointer(nil)) {
-			return errors.New("failed to create mouse move event")
+	case ActionClick:
+		button, err := action.button()
+		if err != nil {
+			return err
 		}
-		C.CGEventPost(C.kCGSessionEventTap, mouseEvent)
-		C.CFRelease(C.CFTypeRef(mouseEvent))
-
-		clickEvent := C.CGEventCreateMouseEvent(source, C.kCGEventLeftMouseDown, newPoint, C.kCGMouseButtonLeft)
-		if clickEvent == (C.CGEventRef)(unsafe.Pointer(nil)) {
-			return errors.New("failed to create mouse down event")
+		location, err := cursorLocation(source)
+		if err != nil {
+			return err
+		}
+		down, up, cgButton := C.CGEventType(C.kCGEventLeftMouseDown), C.CGEventType(C.kCGEventLeftMouseUp), C.CGMouseButton(C.kCGMouseButtonLeft)
+		switch button {
+		case "right":
+			down, up, cgButton = C.kCGEventRightMouseDown, C.kCGEventRightMouseUp, C.kCGMouseButtonRight
+		case "middle":
+			down, up, cgButton = C.kCGEventOtherMouseDown, C.kCGEventOtherMouseUp, C.kCGMouseButtonCenter
+		}
+		if err := postMouseEvent(source, down, location, cgButton); err != nil {
+			return err
 		}
-		C.CGEventPost(C.kCGSessionEventTap, clickEvent)
-		C.CFRelease(C.CFTypeRef(clickEvent))
-
 		C.usleep(10000)
-
-		clickUpEvent := C.CGEventCreateMouseEvent(source, C.kCGEventLeftMouseUp, newPoint, C.kCGMouseButtonLeft)
-		if clickUpEvent == (C.CGEventRef)(unsafe.Pointer(nil)) {
-			return errors.New("failed to create mouse up event")
+		if err := postMouseEvent(source, up, location, cgButton); err != nil {
+			return err
 		}
-		C.CGEventPost(C.kCGSessionEventTap, clickUpEvent)
-		C.CFRelease(C.CFTypeRef(clickUpEvent))
+		logger.LogInfo("macOS: simulated " + button + " click")
+		return nil
 
-		logger.LogInfo("macOS: simulated mouse move and click")
+	case ActionScroll:
+		event := C.createScrollWheelEvent(source, C.int32_t(action.Scroll))
+		if event == (C.CGEventRef)(unsafe.Pointer(nil)) {
+			return errors.New("failed to create scroll wheel event")
+		}
+		C.CGEventPost(C.kCGSessionEventTap, event)
+		C.CFRelease(C.CFTypeRef(event))
+		logger.LogInfo(fmt.Sprintf("macOS: simulated scroll (%d)", action.Scroll))
 		return nil
 
 	default:
-		return errors.New("unsupported mode for CallSendInput on macOS")
+		return fmt.Errorf("unsupported action type for CallPerform on macOS: %s", action.Type)
+	}
+}
+
+// cursorLocation 取得目前游標位置
+func cursorLocation(source C.CGEventSourceRef) (C.CGPoint, error) {
+	event := C.CGEventCreate(source)
+	if event == (C.CGEventRef)(unsafe.Pointer(nil)) {
+		return C.CGPoint{}, errors.New("failed to create event for mouse location")
 	}
+	defer C.CFRelease(C.CFTypeRef(event))
+	return C.CGEventGetLocation(event), nil
+}
+
+// postMouseEvent 建立並送出一筆滑鼠事件
+func postMouseEvent(source C.CGEventSourceRef, typ C.CGEventType, p C.CGPoint, button C.CGMouseButton) error {
+	event := C.CGEventCreateMouseEvent(source, typ, p, button)
+	if event == (C.CGEventRef)(unsafe.Pointer(nil)) {
+		return errors.New("failed to create mouse event")
+	}
+	C.CGEventPost(C.kCGSessionEventTap, event)
+	C.CFRelease(C.CFTypeRef(event))
+	return nil
 }
 
 // GetIdleTime 使用 CGEventSourceSecondsSinceLastEventType 取得系統閒置時間（以秒計），並轉換為 time.Duration。
@@ -158,3 +210,12 @@ func GetIdleTime() (time.Duration, error) {
 	}
 	return time.Duration(idleSeconds * float64(time.Second)), nil
 }
+
+// probeNativeInput、checkNativeInput、probeNativeIdle 與 probeNativePower 在 macOS 上一律可用
+func probeNativeInput() error { return nil }
+
+func checkNativeInput() error { return nil }
+
+func probeNativeIdle() error { return nil }
+
+func probeNativePower() error { return nil }
@@ -0,0 +1,73 @@
+package preventidle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/macro"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// CompileMacro 解析設定中名為 name 的巨集，並檢查按鍵名稱等需依平台對照表判斷的內容
+func CompileMacro(cfg *config.IdlePreventionConfig, name string) (*macro.Macro, error) {
+	src, ok := cfg.Macros[name]
+	if !ok {
+		return nil, fmt.Errorf("no macro named %q", name)
+	}
+	m, err := macro.Parse(name, src)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range m.Statements {
+		if a, ok := statementAction(st); ok {
+			if err := a.Validate(); err != nil {
+				return nil, &macro.SyntaxError{Macro: name, Pos: st.Pos, Msg: err.Error()}
+			}
+		}
+	}
+	return m, nil
+}
+
+// RunMacro 依序執行巨集指令：wait 直接等待，其餘指令轉為 SimulateAction 交給 injector。
+// 若巨集含有會產生可見文字或點擊的指令而未允許，則不執行任何指令。
+func RunMacro(injector InputInjector, m *macro.Macro, allowVisible bool) error {
+	if !allowVisible {
+		for _, st := range m.Statements {
+			if a, ok := statementAction(st); ok && a.Visible() {
+				return fmt.Errorf("refuse to run macro %q (line %d, column %d: %s): %w",
+					m.Name, st.Pos.Line, st.Pos.Col, a, ErrVisibleAction)
+			}
+		}
+	}
+
+	for _, st := range m.Statements {
+		a, ok := statementAction(st)
+		if !ok {
+			time.Sleep(st.Duration)
+			continue
+		}
+		if err := injector.Perform(a); err != nil {
+			return fmt.Errorf("macro %q line %d, column %d: %s failed: %w", m.Name, st.Pos.Line, st.Pos.Col, a, err)
+		}
+	}
+	logger.LogInfo(fmt.Sprintf("Simulated macro %s", m.Name))
+	return nil
+}
+
+// statementAction 將巨集指令轉為 SimulateAction；wait 不對應任何動作
+func statementAction(st macro.Statement) (SimulateAction, bool) {
+	switch st.Op {
+	case macro.OpPress:
+		return SimulateAction{Type: ActionKey, Key: st.Key}, true
+	case macro.OpMove:
+		// 巨集的 move 只移動一次，不自動移回，由巨集自行安排回程
+		return SimulateAction{Type: ActionMouse, DX: st.DX, DY: st.DY, Moves: []Move{{st.DX, st.DY}}}, true
+	case macro.OpScroll:
+		return SimulateAction{Type: ActionScroll, Scroll: st.Amount}, true
+	case macro.OpClick:
+		return SimulateAction{Type: ActionClick, Button: st.Button}, true
+	default:
+		return SimulateAction{}, false
+	}
+}
@@ -0,0 +1,67 @@
+package preventidle
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestSimulateActivity_RunsMacro(t *testing.T) {
+	fake := NewFakeBackend()
+	cfg := &config.IdlePreventionConfig{
+		Mode:   "mixed",
+		Macros: map[string]string{"nudge": "press shift; wait 30ms; move 3,0; wait 20ms; move -3,0; scroll 0"},
+		Macro:  "nudge",
+	}
+
+	start := time.Now()
+	if err := SimulateActivity(fake, cfg, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected macro waits to be honored, took %v", elapsed)
+	}
+
+	if got, want := fake.Inputs(), []string{"key", "mouse", "mouse", "scroll"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected inputs %v, got %v", want, got)
+	}
+	if got, want := fake.Trajectory(), []Move{{3, 0}, {-3, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected trajectory %v, got %v", want, got)
+	}
+	if a := fake.Actions(); a[0].Key != "shift" || a[3].Type != ActionScroll || a[3].Scroll != 0 {
+		t.Errorf("Unexpected actions %+v", a)
+	}
+}
+
+func TestRunMacro_RefusesVisible(t *testing.T) {
+	cfg := &config.IdlePreventionConfig{Macros: map[string]string{"typing": "press shift\npress space"}}
+	m, err := CompileMacro(cfg, "typing")
+	if err != nil {
+		t.Fatalf("CompileMacro failed: %v", err)
+	}
+
+	fake := NewFakeBackend()
+	err = RunMacro(fake, m, false)
+	if !errors.Is(err, ErrVisibleAction) || !strings.Contains(err.Error(), "line 2, column 1") {
+		t.Errorf("Expected visible refusal at line 2, got %v", err)
+	}
+	if len(fake.Inputs()) != 0 {
+		t.Errorf("Expected nothing sent, got %v", fake.Inputs())
+	}
+
+	if err := RunMacro(fake, m, true); err != nil {
+		t.Errorf("Expected macro allowed with allowVisible, got %v", err)
+	}
+}
+
+func TestCompileMacro_UnknownKeyPosition(t *testing.T) {
+	cfg := &config.IdlePreventionConfig{Macros: map[string]string{"bad": "wait 1ms\n  press hyper"}}
+	_, err := CompileMacro(cfg, "bad")
+	if err == nil || !strings.Contains(err.Error(), "line 2, column 3") {
+		t.Errorf("Expected unknown key error at line 2, column 3, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package preventidle
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// ModeExecutor 執行一次模式的模擬活動；rng 由 SimulateActivity 提供，不會是 nil
+type ModeExecutor func(injector InputInjector, cfg *config.IdlePreventionConfig, rng *rand.Rand) error
+
+// Mode 描述一個閒置防護模式。
+// Execute 為 nil 時，依 Actions 列出的動作類型送出預設動作；兩者皆空代表此模式不送出任何輸入（例如 assert）。
+type Mode struct {
+	Validate config.ModeValidator
+	Execute  ModeExecutor
+	Actions  []string
+}
+
+var (
+	modesMu sync.RWMutex
+	modes   = map[string]Mode{}
+)
+
+func init() {
+	RegisterMode("key", Mode{Actions: []string{ActionKey}})
+	RegisterMode("mouse", Mode{Actions: []string{ActionMouse}})
+	RegisterMode("mixed", Mode{Actions: []string{ActionKey, ActionMouse}})
+	RegisterMode(ModeAssert, Mode{})
+}
+
+// RegisterMode 以名稱註冊模式，驗證函式同時註冊到 config，讓設定檢查與執行使用同一份模式清單
+func RegisterMode(name string, m Mode) {
+	for _, t := range m.Actions {
+		if _, err := DefaultAction(t); err != nil {
+			panic(fmt.Sprintf("register mode %s: %v", name, err))
+		}
+	}
+	modesMu.Lock()
+	modes[name] = m
+	modesMu.Unlock()
+	config.RegisterMode(name, m.Validate)
+}
+
+// lookupMode 回傳已註冊的模式；未註冊時回傳與 config.ValidateConfig 相同的 *config.InvalidModeError
+func lookupMode(name string) (Mode, error) {
+	if _, err := config.LookupMode(name); err != nil {
+		return Mode{}, err
+	}
+	modesMu.RLock()
+	m, ok := modes[name]
+	modesMu.RUnlock()
+	if !ok {
+		return Mode{}, fmt.Errorf("idle prevention mode %s has no executor; register it with preventidle.RegisterMode", name)
+	}
+	return m, nil
+}
@@ -0,0 +1,83 @@
+package preventidle
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestRegisterMode_ThirdParty(t *testing.T) {
+	var ran bool
+	RegisterMode("test-wiggle", Mode{
+		Validate: func(cfg *config.IdlePreventionConfig) error {
+			if len(cfg.Actions) > 0 {
+				return errors.New("test-wiggle does not take actions")
+			}
+			return nil
+		},
+		Execute: func(injector InputInjector, cfg *config.IdlePreventionConfig, rng *rand.Rand) error {
+			ran = true
+			return injector.Perform(SimulateAction{Type: ActionMouse, DX: 2})
+		},
+	})
+
+	cfg := &config.APPConfig{
+		Scheduler:      config.SchedulerConfig{Interval: time.Minute},
+		IdlePrevention: config.IdlePreventionConfig{Interval: 5 * time.Minute, Mode: "test-wiggle"},
+		RetryPolicy:    config.RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected registered mode to validate, got %v", err)
+	}
+	cfg.IdlePrevention.Actions = []config.ActionConfig{{Type: "key", Key: "Shift"}}
+	if err := config.ValidateConfig(cfg); err == nil {
+		t.Error("Expected mode validator to reject actions, got nil")
+	}
+
+	fake := NewFakeBackend()
+	if err := SimulateActivity(fake, &config.IdlePreventionConfig{Mode: "test-wiggle"}, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	if !ran || len(fake.Inputs()) != 1 {
+		t.Errorf("Expected registered executor to run once, got ran=%v inputs=%v", ran, fake.Inputs())
+	}
+}
+
+func TestUnknownMode_SameErrorEverywhere(t *testing.T) {
+	cfg := &config.APPConfig{
+		Scheduler:      config.SchedulerConfig{Interval: time.Minute},
+		IdlePrevention: config.IdlePreventionConfig{Interval: 5 * time.Minute, Mode: "KEY"},
+		RetryPolicy:    config.RetryPolicyConfig{RetryInterval: "10s"},
+	}
+	validateErr := config.ValidateConfig(cfg)
+
+	fake := NewFakeBackend()
+	simulateErr := SimulateActivity(fake, &cfg.IdlePrevention, nil)
+	_, actionsErr := ActionsFor(&cfg.IdlePrevention)
+
+	for _, err := range []error{validateErr, simulateErr, actionsErr} {
+		var modeErr *config.InvalidModeError
+		if !errors.As(err, &modeErr) || modeErr.Mode != "KEY" {
+			t.Fatalf("Expected *config.InvalidModeError for KEY, got %v", err)
+		}
+		if err.Error() != validateErr.Error() {
+			t.Errorf("Expected %q, got %q", validateErr, err)
+		}
+	}
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no input for unknown mode, got %v", inputs)
+	}
+}
+
+func TestSimulateActivity_AssertModeSendsNothing(t *testing.T) {
+	fake := NewFakeBackend()
+	if err := SimulateActivity(fake, &config.IdlePreventionConfig{Mode: ModeAssert}, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 0 {
+		t.Errorf("Expected no input in assert mode, got %v", inputs)
+	}
+}
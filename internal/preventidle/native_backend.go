@@ -0,0 +1,55 @@
+package preventidle
+
+import (
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// NativeBackendName 為目前作業系統預設 API 所組成的後端名稱
+const NativeBackendName = "native"
+
+// 以下型別將各平台的 CallSendInput、GetIdleTime、PreventSleep 與 AllowIdle 包裝成介面，
+// 並各自透過 probeNative* 檢查目前環境是否可用。
+type (
+	nativeInjector struct{}
+	nativeIdle     struct{}
+	nativePower    struct{}
+)
+
+func (nativeInjector) SendInput(inputType string) error { return CallSendInput(inputType) }
+
+func (nativeInjector) Perform(action SimulateAction) error { return CallPerform(action) }
+
+func (nativeInjector) Probe() error { return probeNativeInput() }
+
+func (nativeIdle) IdleTime() (time.Duration, error) { return GetIdleTime() }
+
+func (nativeIdle) Probe() error { return probeNativeIdle() }
+
+func (nativePower) PreventSleep() error { return PreventSleep() }
+
+func (nativePower) AllowIdle() error { return AllowIdle() }
+
+func (nativePower) Probe() error { return probeNativePower() }
+
+func init() {
+	RegisterBackend(NativeBackendName, func(*config.IdlePreventionConfig) (*Backend, error) {
+		return &Backend{
+			Name:     NativeBackendName,
+			Injector: nativeInjector{},
+			Idle:     nativeIdle{},
+			Power:    nativePower{},
+		}, nil
+	})
+	RegisterIdleSource(NativeBackendName, func(*config.IdlePreventionConfig) (*Backend, error) {
+		return &Backend{Name: NativeBackendName, Idle: nativeIdle{}}, nil
+	})
+	RegisterCapabilities(NativeBackendName, func(*config.IdlePreventionConfig) Capabilities {
+		return Capabilities{
+			Input: checkNativeInput() == nil,
+			Idle:  probeNativeIdle() == nil,
+			Power: probeNativePower() == nil,
+		}
+	})
+}
@@ -0,0 +1,5 @@
+package preventidle
+
+// UinputDevicePath 為 Linux 建立虛擬輸入裝置所使用的 uinput 路徑，
+// 測試時可指向假的字元裝置或管線以檢查寫入的 input_event。
+var UinputDevicePath = "/dev/uinput"
@@ -0,0 +1,164 @@
+package preventidle
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// 滑鼠移動樣式名稱
+const (
+	PatternOutAndBack     = "out-and-back"
+	PatternCircle         = "circle"
+	PatternRandomWalk     = "random-walk"
+	PatternReturnToOrigin = "return-to-origin"
+)
+
+const (
+	defaultPatternRadius = 3
+	defaultPatternSteps  = 8
+)
+
+// Move 為一次相對滑鼠位移
+type Move struct {
+	DX int32
+	DY int32
+}
+
+// MousePattern 依動作的半徑與步數產生一串相對位移
+type MousePattern func(a SimulateAction, rng *rand.Rand) []Move
+
+var mousePatterns = map[string]MousePattern{
+	PatternOutAndBack:     outAndBack,
+	PatternCircle:         circle,
+	PatternRandomWalk:     randomWalk,
+	PatternReturnToOrigin: returnToOrigin,
+}
+
+// PatternNames 回傳所有可用的滑鼠移動樣式名稱（已排序）
+func PatternNames() []string {
+	names := make([]string, 0, len(mousePatterns))
+	for name := range mousePatterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolvePattern 依 Pattern 產生 Moves；未指定樣式或已有 Moves 時原樣回傳
+func resolvePattern(a SimulateAction, rng *rand.Rand) (SimulateAction, error) {
+	if a.Type != ActionMouse || a.Pattern == "" || len(a.Moves) > 0 {
+		return a, nil
+	}
+	pattern, ok := mousePatterns[a.Pattern]
+	if !ok {
+		return a, fmt.Errorf("unknown mouse pattern: %s", a.Pattern)
+	}
+	a.Moves = pattern(a, rng)
+	return a, nil
+}
+
+// Trajectory 回傳滑鼠動作實際要播放的相對位移：
+// 有 Moves 時使用之，否則為 (DX, DY) 出去再回來
+func (a SimulateAction) Trajectory() []Move {
+	if len(a.Moves) > 0 {
+		return a.Moves
+	}
+	return []Move{{a.DX, a.DY}, {-a.DX, -a.DY}}
+}
+
+// playMoves 依序播放滑鼠動作的相對位移，步與步之間等待 StepDelay
+func playMoves(a SimulateAction, move func(dx, dy int32) error) error {
+	for i, m := range a.Trajectory() {
+		if i > 0 && a.StepDelay > 0 {
+			time.Sleep(a.StepDelay)
+		}
+		if err := move(m.DX, m.DY); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a SimulateAction) radius() int32 {
+	if a.Radius > 0 {
+		return a.Radius
+	}
+	return defaultPatternRadius
+}
+
+func (a SimulateAction) steps() int {
+	if a.Steps > 0 {
+		return a.Steps
+	}
+	return defaultPatternSteps
+}
+
+// outAndBack 移動 (DX, DY) 後移回；未指定位移時向右移動半徑的距離
+func outAndBack(a SimulateAction, _ *rand.Rand) []Move {
+	dx, dy := a.DX, a.DY
+	if dx == 0 && dy == 0 {
+		dx = a.radius()
+	}
+	return []Move{{dx, dy}, {-dx, -dy}}
+}
+
+// circle 以起點為圓周上的一點繞行一圈，最後回到起點
+func circle(a SimulateAction, _ *rand.Rand) []Move {
+	r, n := float64(a.radius()), a.steps()
+	var moves []Move
+	var x, y int32
+	for k := 1; k <= n; k++ {
+		var nx, ny int32
+		if k < n {
+			theta := 2 * math.Pi * float64(k) / float64(n)
+			nx = int32(math.Round(r*math.Cos(theta) - r))
+			ny = int32(math.Round(r * math.Sin(theta)))
+		}
+		if nx != x || ny != y {
+			moves = append(moves, Move{nx - x, ny - y})
+		}
+		x, y = nx, ny
+	}
+	return moves
+}
+
+// randomWalk 隨機移動 Steps 步，游標始終保持在起點半徑範圍內，結束時不回到起點
+func randomWalk(a SimulateAction, rng *rand.Rand) []Move {
+	moves, _, _ := walk(a, rng)
+	return moves
+}
+
+// returnToOrigin 先隨機移動，最後一步直接回到起點
+func returnToOrigin(a SimulateAction, rng *rand.Rand) []Move {
+	moves, x, y := walk(a, rng)
+	if x != 0 || y != 0 {
+		moves = append(moves, Move{-x, -y})
+	}
+	return moves
+}
+
+// walk 產生半徑範圍內的隨機位移，並回傳最終相對起點的位置
+func walk(a SimulateAction, rng *rand.Rand) ([]Move, int32, int32) {
+	r := a.radius()
+	step := max(r/2, 1)
+	var moves []Move
+	var x, y int32
+	for i := 0; i < a.steps(); i++ {
+		// 超出半徑的候選位移重抽，多次失敗則略過這一步
+		for try := 0; try < 8; try++ {
+			dx := rng.Int32N(2*step+1) - step
+			dy := rng.Int32N(2*step+1) - step
+			nx, ny := x+dx, y+dy
+			if (dx == 0 && dy == 0) || nx*nx+ny*ny > r*r {
+				continue
+			}
+			moves = append(moves, Move{dx, dy})
+			x, y = nx, ny
+			break
+		}
+	}
+	return moves, x, y
+}
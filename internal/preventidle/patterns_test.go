@@ -0,0 +1,110 @@
+package preventidle
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// positions 將相對位移累加為相對起點的位置
+func positions(moves []Move) []Move {
+	var x, y int32
+	out := make([]Move, 0, len(moves))
+	for _, m := range moves {
+		x, y = x+m.DX, y+m.DY
+		out = append(out, Move{x, y})
+	}
+	return out
+}
+
+func simulatePattern(t *testing.T, a config.ActionConfig) []Move {
+	t.Helper()
+	fake := NewFakeBackend()
+	if err := SimulateActivity(fake, &config.IdlePreventionConfig{Actions: []config.ActionConfig{a}}, nil); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	return fake.Trajectory()
+}
+
+func TestPattern_OutAndBack(t *testing.T) {
+	got := simulatePattern(t, config.ActionConfig{Type: "mouse", Pattern: PatternOutAndBack, DX: 4, DY: -2})
+	want := []Move{{4, -2}, {-4, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPattern_Circle(t *testing.T) {
+	got := simulatePattern(t, config.ActionConfig{Type: "mouse", Pattern: PatternCircle, Radius: 10, Steps: 4})
+	// 起點位於圓周最右側，依序經過上、左、下後回到起點
+	want := []Move{{-10, 10}, {-10, -10}, {10, -10}, {10, 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPattern_CircleReturnsToOrigin(t *testing.T) {
+	got := simulatePattern(t, config.ActionConfig{Type: "mouse", Pattern: PatternCircle})
+	if len(got) == 0 {
+		t.Fatal("Expected circle moves, got none")
+	}
+	if p := positions(got); p[len(p)-1] != (Move{}) {
+		t.Errorf("Expected circle to end at origin, ended at %v", p[len(p)-1])
+	}
+}
+
+func TestPattern_RandomWalkStaysWithinRadius(t *testing.T) {
+	a := SimulateAction{Type: ActionMouse, Pattern: PatternRandomWalk, Radius: 4, Steps: 50}
+	for seed := uint64(0); seed < 20; seed++ {
+		moves := randomWalk(a, rand.New(rand.NewPCG(seed, seed)))
+		if len(moves) == 0 {
+			t.Fatalf("seed %d: expected random walk moves", seed)
+		}
+		for _, p := range positions(moves) {
+			if p.DX*p.DX+p.DY*p.DY > 16 {
+				t.Fatalf("seed %d: position %v outside radius 4", seed, p)
+			}
+		}
+	}
+}
+
+func TestPattern_ReturnToOrigin(t *testing.T) {
+	a := SimulateAction{Type: ActionMouse, Pattern: PatternReturnToOrigin, Radius: 5, Steps: 12}
+	for seed := uint64(0); seed < 20; seed++ {
+		p := positions(returnToOrigin(a, rand.New(rand.NewPCG(seed, 1))))
+		if len(p) == 0 || p[len(p)-1] != (Move{}) {
+			t.Fatalf("seed %d: expected walk to end at origin, got %v", seed, p)
+		}
+	}
+}
+
+func TestPattern_UnknownName(t *testing.T) {
+	_, err := ActionsFor(&config.IdlePreventionConfig{
+		Actions: []config.ActionConfig{{Type: "mouse", Pattern: "figure-eight"}},
+	})
+	if err == nil {
+		t.Error("Expected error for unknown pattern, got nil")
+	}
+}
+
+func TestPlayMoves_StepDelay(t *testing.T) {
+	a := SimulateAction{Type: ActionMouse, Moves: []Move{{1, 0}, {0, 1}, {-1, -1}}, StepDelay: 20 * time.Millisecond}
+	var played []Move
+	start := time.Now()
+	err := playMoves(a, func(dx, dy int32) error {
+		played = append(played, Move{dx, dy})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("playMoves failed: %v", err)
+	}
+	if !reflect.DeepEqual(played, a.Moves) {
+		t.Errorf("Expected %v played, got %v", a.Moves, played)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected at least two step delays, took %v", elapsed)
+	}
+}
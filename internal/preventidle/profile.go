@@ -0,0 +1,156 @@
+package preventidle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// ProfileVersion 為目前的活動節奏檔格式版本
+const ProfileVersion = 1
+
+// ProfileKindUnknown 為無法分辨鍵盤或滑鼠的活動在節奏檔中的類型
+const ProfileKindUnknown = "unknown"
+
+// profileGapBounds 為活動間隔分布各區間的上界，最後一個區間收納更長的間隔
+var profileGapBounds = []time.Duration{
+	250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second,
+	10 * time.Second, 30 * time.Second, time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour, 24 * time.Hour,
+}
+
+// GapBucket 為活動間隔分布的一個區間：上一個區間的上界到 MaxMs（毫秒）之間的間隔次數
+type GapBucket struct {
+	MaxMs int64 `json:"maxMs"`
+	Count int   `json:"count"`
+}
+
+// ActivityProfile 為錄製的真實輸入節奏。只記錄事件類型的次數與活動間隔的分布，
+// 不含任何按鍵內容或時間戳序列。
+type ActivityProfile struct {
+	Version    int            `json:"version"`
+	RecordedAt time.Time      `json:"recordedAt"`
+	DurationMs int64          `json:"durationMs"` // 錄製時長
+	Source     string         `json:"source"`     // 錄製時使用的閒置來源
+	Events     int            `json:"events"`
+	Kinds      map[string]int `json:"kinds"` // "key"、"mouse"、"unknown" 各自的次數
+	Gaps       []GapBucket    `json:"gaps"`
+}
+
+// NewActivityProfile 建立空的節奏檔
+func NewActivityProfile(source string, recordedAt time.Time) *ActivityProfile {
+	p := &ActivityProfile{
+		Version:    ProfileVersion,
+		RecordedAt: recordedAt,
+		Source:     source,
+		Kinds:      map[string]int{},
+	}
+	for _, b := range profileGapBounds {
+		p.Gaps = append(p.Gaps, GapBucket{MaxMs: b.Milliseconds()})
+	}
+	return p
+}
+
+// AddEvent 記錄一次活動的類型，kind 為空時記為 unknown
+func (p *ActivityProfile) AddEvent(kind string) {
+	if kind == "" {
+		kind = ProfileKindUnknown
+	}
+	p.Events++
+	p.Kinds[kind]++
+}
+
+// AddGap 把兩次活動之間的間隔計入對應的區間
+func (p *ActivityProfile) AddGap(d time.Duration) {
+	ms := d.Milliseconds()
+	for i := range p.Gaps {
+		if ms <= p.Gaps[i].MaxMs || i == len(p.Gaps)-1 {
+			p.Gaps[i].Count++
+			return
+		}
+	}
+}
+
+// SampleGap 依分布隨機取一個活動間隔：先依次數選區間，再於區間內均勻取值
+func (p *ActivityProfile) SampleGap(rng *rand.Rand) time.Duration {
+	total := 0
+	for _, b := range p.Gaps {
+		total += b.Count
+	}
+	if total == 0 {
+		return 0
+	}
+	r := rng.IntN(total)
+	var lower int64
+	for _, b := range p.Gaps {
+		if r < b.Count {
+			return time.Duration(lower+rng.Int64N(b.MaxMs-lower+1)) * time.Millisecond
+		}
+		r -= b.Count
+		lower = b.MaxMs
+	}
+	return 0
+}
+
+// SampleKind 依鍵盤與滑鼠的使用比例隨機取一種動作類型；沒有可分辨的紀錄時各半
+func (p *ActivityProfile) SampleKind(rng *rand.Rand) string {
+	keys, mice := p.Kinds[ActionKey], p.Kinds[ActionMouse]
+	if keys+mice == 0 {
+		keys, mice = 1, 1
+	}
+	if rng.IntN(keys+mice) < keys {
+		return ActionKey
+	}
+	return ActionMouse
+}
+
+// Validate 檢查節奏檔的版本與分布區間
+func (p *ActivityProfile) Validate() error {
+	if p.Version != ProfileVersion {
+		return fmt.Errorf("unsupported activity profile version %d (want %d)", p.Version, ProfileVersion)
+	}
+	var lower int64 = -1
+	total := 0
+	for _, b := range p.Gaps {
+		if b.MaxMs <= lower || b.Count < 0 {
+			return errors.New("activity profile gaps must have increasing bounds and non-negative counts")
+		}
+		lower = b.MaxMs
+		total += b.Count
+	}
+	if total == 0 {
+		return errors.New("activity profile has no recorded gaps")
+	}
+	return nil
+}
+
+// LoadProfile 讀取並檢查節奏檔
+func LoadProfile(path string) (*ActivityProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p ActivityProfile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse activity profile %s failed: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("activity profile %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// SaveProfile 將節奏檔原子性地寫入 path（先寫暫存檔再 rename）
+func SaveProfile(path string, p *ActivityProfile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
@@ -0,0 +1,155 @@
+package preventidle
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestActivityProfile_SampleGapWithinRecordedBucket(t *testing.T) {
+	p := NewActivityProfile("test", time.Now())
+	for i := 0; i < 5; i++ {
+		p.AddGap(3 * time.Second) // 2s–5s 區間
+	}
+	p.AddGap(48 * time.Hour) // 超過最後一個上界，仍計入最後一個區間
+
+	rng := NewRand(1)
+	for i := 0; i < 200; i++ {
+		d := p.SampleGap(rng)
+		if (d < 2*time.Second || d > 5*time.Second) && (d < time.Hour || d > 24*time.Hour) {
+			t.Fatalf("Sampled gap %v outside recorded buckets", d)
+		}
+	}
+}
+
+func TestActivityProfile_SampleKindFollowsRatio(t *testing.T) {
+	p := NewActivityProfile("test", time.Now())
+	for i := 0; i < 9; i++ {
+		p.AddEvent(ActionMouse)
+	}
+	p.AddEvent(ActionKey)
+	p.AddEvent("")
+
+	if p.Events != 11 || p.Kinds[ProfileKindUnknown] != 1 {
+		t.Fatalf("Unexpected event counts %d %v", p.Events, p.Kinds)
+	}
+	rng := NewRand(1)
+	mice := 0
+	for i := 0; i < 1000; i++ {
+		if p.SampleKind(rng) == ActionMouse {
+			mice++
+		}
+	}
+	if mice < 850 || mice > 950 {
+		t.Errorf("Expected about 90%% mouse, got %d/1000", mice)
+	}
+}
+
+func TestSaveAndLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.json")
+	p := NewActivityProfile("evdev", time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC))
+	p.AddEvent(ActionKey)
+	p.AddGap(400 * time.Millisecond)
+	if err := SaveProfile(path, p); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	loaded, err := LoadProfile(path)
+	if err != nil {
+		t.Fatalf("LoadProfile failed: %v", err)
+	}
+	if loaded.Source != "evdev" || loaded.Kinds[ActionKey] != 1 || loaded.Gaps[1].Count != 1 {
+		t.Errorf("Unexpected loaded profile %+v", loaded)
+	}
+
+	empty := NewActivityProfile("evdev", time.Now())
+	if err := SaveProfile(path, empty); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	if _, err := LoadProfile(path); err == nil || !strings.Contains(err.Error(), "no recorded gaps") {
+		t.Errorf("Expected error for empty profile, got %v", err)
+	}
+}
+
+// kindedIdle 為可指定最後輸入類型的假閒置來源
+type kindedIdle struct {
+	*FakeBackend
+	kind string
+}
+
+func (k *kindedIdle) LastInputKind() string { return k.kind }
+
+func TestRecorder_DetectsActivityFromIdleResets(t *testing.T) {
+	idle := &kindedIdle{FakeBackend: NewFakeBackend()}
+	t0 := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC)
+	r := NewRecorder(idle, "fake", t0)
+
+	steps := []struct {
+		at   time.Duration
+		idle time.Duration
+		kind string
+	}{
+		{0, 30 * time.Second, ""},                                // 基準：最後活動在 08:59:30
+		{time.Second, 31 * time.Second, ""},                      // 沒有新活動
+		{2 * time.Second, 500 * time.Millisecond, "key"},         // 間隔 31.5s
+		{3 * time.Second, 1500 * time.Millisecond, ""},           // 同一次活動
+		{4 * time.Second, 0, "mouse"},                            // 間隔 2.5s
+		{10 * time.Second, 6*time.Second + time.Millisecond, ""}, // 取樣誤差內
+	}
+	for _, s := range steps {
+		idle.SetIdle(s.idle)
+		idle.kind = s.kind
+		if err := r.Sample(t0.Add(s.at)); err != nil {
+			t.Fatalf("Sample failed: %v", err)
+		}
+	}
+
+	p := r.Profile(t0.Add(time.Minute))
+	if p.Events != 2 || p.Kinds[ActionKey] != 1 || p.Kinds[ActionMouse] != 1 || p.DurationMs != 60000 {
+		t.Errorf("Unexpected profile events %d kinds %v duration %d", p.Events, p.Kinds, p.DurationMs)
+	}
+	counts := map[int64]int{}
+	for _, b := range p.Gaps {
+		if b.Count > 0 {
+			counts[b.MaxMs] = b.Count
+		}
+	}
+	if len(counts) != 2 || counts[5000] != 1 || counts[60000] != 1 {
+		t.Errorf("Expected gaps in the 5s and 1m buckets, got %v", counts)
+	}
+}
+
+func TestReplayMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.json")
+	p := NewActivityProfile("evdev", time.Now())
+	for i := 0; i < 4; i++ {
+		p.AddEvent(ActionMouse)
+		p.AddGap(100 * time.Millisecond)
+	}
+	if err := SaveProfile(path, p); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	cfg := &config.IdlePreventionConfig{
+		Mode:   ModeReplay,
+		Replay: config.ReplayConfig{Profile: path, MaxGap: time.Second, MaxEvents: 3},
+	}
+	if err := config.ValidateMode(cfg); err != nil {
+		t.Fatalf("Expected replay mode to validate, got %v", err)
+	}
+	fake := NewFakeBackend()
+	if err := SimulateActivity(fake, cfg, NewRand(1)); err != nil {
+		t.Fatalf("SimulateActivity failed: %v", err)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 3 || inputs[0] != ActionMouse {
+		t.Errorf("Expected 3 mouse events replayed, got %v", inputs)
+	}
+
+	cfg.Replay.Profile = filepath.Join(t.TempDir(), "missing.json")
+	if err := config.ValidateMode(cfg); err == nil {
+		t.Error("Expected error for missing profile, got nil")
+	}
+}
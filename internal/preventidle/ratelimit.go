@@ -0,0 +1,173 @@
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+// ErrRateLimited 表示已達到 rateLimit 的上限，模擬被略過
+var ErrRateLimited = errors.New("simulation rate limit reached")
+
+// quota 為一項頻率上限：allows 回報 now 時是否還能再送出一個事件，take 扣除一個事件
+type quota interface {
+	allows(now time.Time) bool
+	take()
+	String() string
+}
+
+// tokenBucket 以固定速率補充 capacity 個權杖，每個事件消耗一個
+type tokenBucket struct {
+	name     string
+	capacity float64
+	per      time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(name string, capacity int, per time.Duration) *tokenBucket {
+	return &tokenBucket{name: name, capacity: float64(capacity), per: per, tokens: float64(capacity)}
+}
+
+// refill 依距上次補充的時間補回權杖，最多補滿 capacity
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.capacity / b.per.Seconds()
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+}
+
+func (b *tokenBucket) allows(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= 1
+}
+
+func (b *tokenBucket) take() { b.tokens-- }
+
+func (b *tokenBucket) String() string {
+	return fmt.Sprintf("at most %d events per %s", int(b.capacity), b.name)
+}
+
+// fixedWindow 在固定的時窗（整點開始的一小時或午夜開始的一天，依當地時間）內最多允許 limit 個事件，
+// 進入下一個時窗時才重新計數，不會在時窗中途補回配額
+type fixedWindow struct {
+	name  string
+	limit int
+	start func(now time.Time) time.Time
+	begin time.Time
+	used  int
+}
+
+func (w *fixedWindow) allows(now time.Time) bool {
+	if start := w.start(now); !start.Equal(w.begin) {
+		w.begin = start
+		w.used = 0
+	}
+	return w.used < w.limit
+}
+
+func (w *fixedWindow) take() { w.used++ }
+
+func (w *fixedWindow) String() string {
+	return fmt.Sprintf("at most %d events per %s", w.limit, w.name)
+}
+
+func hourStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// RateLimiter 限制模擬活動的頻率，避免設定錯誤的排程間隔在桌面上大量送出按鍵。
+// 每分鐘的上限為權杖桶，每小時與每天的上限為固定時窗。
+// 每個實際送出的動作（按鍵、點擊、捲動或一段滑鼠移動）算一個事件，
+// 因此重播、巨集或一次送出多個動作的模式會依動作數計算。
+type RateLimiter struct {
+	mu     sync.Mutex
+	quotas []quota
+	now    func() time.Time
+}
+
+// NewRateLimiter 依設定建立限制器；各上限為 0 代表不限制
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{now: time.Now}
+	if cfg.PerMinute > 0 {
+		l.quotas = append(l.quotas, newTokenBucket("minute", cfg.PerMinute, time.Minute))
+	}
+	if cfg.PerHour > 0 {
+		l.quotas = append(l.quotas, &fixedWindow{name: "hour", limit: cfg.PerHour, start: hourStart})
+	}
+	if cfg.PerDay > 0 {
+		l.quotas = append(l.quotas, &fixedWindow{name: "day", limit: cfg.PerDay, start: dayStart})
+	}
+	return l
+}
+
+// Check 回報是否還能再送出一個事件，不扣除配額；已達上限時回傳包裝 ErrRateLimited 的錯誤
+func (l *RateLimiter) Check() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.check()
+}
+
+// check 與 take 的呼叫端須持有 l.mu
+func (l *RateLimiter) check() error {
+	now := l.now()
+	for _, q := range l.quotas {
+		if !q.allows(now) {
+			return fmt.Errorf("%w: %s", ErrRateLimited, q)
+		}
+	}
+	return nil
+}
+
+func (l *RateLimiter) take() {
+	for _, q := range l.quotas {
+		q.take()
+	}
+}
+
+// Limit 包裝 injector，每個成功送出的事件扣除一個配額。已達上限時不送出並回傳包裝 ErrRateLimited 的錯誤；
+// 送出失敗時不扣配額。injector 為 nil 時回傳 nil。
+func (l *RateLimiter) Limit(injector InputInjector) InputInjector {
+	if injector == nil {
+		return nil
+	}
+	return &limitedInjector{injector: injector, limiter: l}
+}
+
+// limitedInjector 為 RateLimiter.Limit 回傳的注入器
+type limitedInjector struct {
+	injector InputInjector
+	limiter  *RateLimiter
+}
+
+func (i *limitedInjector) SendInput(inputType string) error {
+	return i.send(func() error { return i.injector.SendInput(inputType) })
+}
+
+func (i *limitedInjector) Perform(action SimulateAction) error {
+	return i.send(func() error { return i.injector.Perform(action) })
+}
+
+// send 在送出前檢查配額，送出成功後才扣除；送出時不持有鎖
+func (i *limitedInjector) send(inject func() error) error {
+	if err := i.limiter.Check(); err != nil {
+		return err
+	}
+	if err := inject(); err != nil {
+		return err
+	}
+	i.limiter.mu.Lock()
+	i.limiter.take()
+	i.limiter.mu.Unlock()
+	return nil
+}
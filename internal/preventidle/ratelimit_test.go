@@ -0,0 +1,141 @@
+package preventidle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+)
+
+func TestRateLimiter_PerMinuteRefills(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{PerMinute: 2})
+	now := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	injector := l.Limit(NewFakeBackend())
+
+	for i := 0; i < 2; i++ {
+		if err := injector.SendInput("key"); err != nil {
+			t.Fatalf("Expected event %d allowed, got %v", i, err)
+		}
+	}
+	if err := injector.SendInput("key"); err == nil || !strings.Contains(err.Error(), "2 events per minute") {
+		t.Fatalf("Expected per-minute limit error, got %v", err)
+	}
+
+	// 每分鐘 2 個權杖，30 秒補回一個
+	now = now.Add(30 * time.Second)
+	if err := injector.SendInput("key"); err != nil {
+		t.Errorf("Expected one token refilled after 30s, got %v", err)
+	}
+	if err := injector.SendInput("key"); err == nil {
+		t.Error("Expected limit reached again, got nil")
+	}
+}
+
+func TestRateLimiter_HourlyQuotaNotConsumedWhenDenied(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{PerMinute: 1, PerHour: 3})
+	now := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	injector := l.Limit(NewFakeBackend())
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		// 每 30 秒嘗試兩次：第二次被每分鐘上限拒絕，不應消耗每小時配額
+		if injector.SendInput("key") == nil {
+			allowed++
+		}
+		if injector.SendInput("key") == nil {
+			allowed++
+		}
+		now = now.Add(time.Minute)
+	}
+	if allowed != 3 {
+		t.Errorf("Expected hourly quota of 3 events, got %d", allowed)
+	}
+	if err := injector.SendInput("key"); err == nil || !strings.Contains(err.Error(), "per hour") {
+		t.Errorf("Expected hourly limit error, got %v", err)
+	}
+}
+
+func TestRateLimiter_HourlyQuotaIsFixedWindow(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{PerHour: 2})
+	now := time.Date(2025, time.April, 7, 9, 10, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	injector := l.Limit(NewFakeBackend())
+
+	for i := 0; i < 2; i++ {
+		if err := injector.SendInput("key"); err != nil {
+			t.Fatalf("Expected event %d allowed, got %v", i, err)
+		}
+	}
+	// 權杖桶在 30 分鐘後會補回一個，固定時窗要等到下一個整點
+	now = now.Add(40 * time.Minute)
+	if err := injector.SendInput("key"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected quota exhausted until 10:00, got %v", err)
+	}
+	now = time.Date(2025, time.April, 7, 10, 0, 0, 0, time.UTC)
+	if err := injector.SendInput("key"); err != nil {
+		t.Errorf("Expected quota reset at the top of the hour, got %v", err)
+	}
+}
+
+func TestRateLimiter_DailyQuotaResetsAtMidnight(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{PerDay: 1})
+	now := time.Date(2025, time.April, 7, 23, 59, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	injector := l.Limit(NewFakeBackend())
+
+	if err := injector.SendInput("key"); err != nil {
+		t.Fatalf("Expected first event allowed, got %v", err)
+	}
+	if err := injector.SendInput("key"); err == nil || !strings.Contains(err.Error(), "1 events per day") {
+		t.Fatalf("Expected daily limit error, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := injector.SendInput("key"); err != nil {
+		t.Errorf("Expected quota reset at midnight, got %v", err)
+	}
+}
+
+func TestRateLimiter_LimitChargesEachSentEvent(t *testing.T) {
+	l := NewRateLimiter(config.RateLimitConfig{PerHour: 3})
+	now := time.Date(2025, time.April, 7, 9, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	fake := NewFakeBackend()
+	injector := l.Limit(fake)
+
+	fake.InputErr = errors.New("device gone")
+	if err := injector.SendInput("key"); !errors.Is(err, fake.InputErr) {
+		t.Fatalf("Expected injector error, got %v", err)
+	}
+	fake.InputErr = nil
+
+	// 一輪送出多個動作：每個動作各算一個事件，失敗的那次不算
+	actions := []SimulateAction{
+		{Type: ActionKey, Key: "Shift"},
+		{Type: ActionMouse, DX: 1},
+		{Type: ActionKey, Key: "F15"},
+		{Type: ActionKey, Key: "Ctrl"},
+	}
+	err := PerformActions(injector, &config.IdlePreventionConfig{}, actions, nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected sequence stopped by rate limit, got %v", err)
+	}
+	if inputs := fake.Inputs(); len(inputs) != 3 {
+		t.Errorf("Expected 3 events sent before the limit, got %v", inputs)
+	}
+	if err := l.Check(); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected quota exhausted, got %v", err)
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	injector := NewRateLimiter(config.RateLimitConfig{}).Limit(NewFakeBackend())
+	for i := 0; i < 1000; i++ {
+		if err := injector.SendInput("key"); err != nil {
+			t.Fatalf("Expected no limit, got %v", err)
+		}
+	}
+}
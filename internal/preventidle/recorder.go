@@ -0,0 +1,87 @@
+package preventidle
+
+import (
+	"fmt"
+	"time"
+)
+
+// recordTolerance 為判定「閒置計數歸零代表一次新活動」時允許的取樣誤差
+const recordTolerance = 20 * time.Millisecond
+
+// Recorder 定期取樣 IdleSource：推算出的最後活動時刻往後移動即視為一次真實活動，
+// 並累積活動間隔的分布；閒置來源能分辨輸入類型時一併記錄鍵盤與滑鼠的比例。
+type Recorder struct {
+	idle    IdleSource
+	kinds   InputKindSource
+	profile *ActivityProfile
+	start   time.Time
+	last    time.Time
+}
+
+// NewRecorder 以 idle 建立錄製器；source 為寫入節奏檔的來源名稱
+func NewRecorder(idle IdleSource, source string, start time.Time) *Recorder {
+	r := &Recorder{idle: idle, profile: NewActivityProfile(source, start), start: start}
+	if k, ok := idle.(InputKindSource); ok {
+		r.kinds = k
+	}
+	return r
+}
+
+// Sample 在 now 取樣一次閒置時間
+func (r *Recorder) Sample(now time.Time) error {
+	idle, err := r.idle.IdleTime()
+	if err != nil {
+		return err
+	}
+	at := now.Add(-idle)
+	if r.last.IsZero() {
+		// 第一次取樣只建立基準，錄製開始前的活動不計入
+		r.last = at
+		return nil
+	}
+	if at.Sub(r.last) <= recordTolerance {
+		return nil
+	}
+
+	kind := ""
+	if r.kinds != nil {
+		kind = r.kinds.LastInputKind()
+	}
+	r.profile.AddEvent(kind)
+	r.profile.AddGap(at.Sub(r.last))
+	r.last = at
+	return nil
+}
+
+// Profile 回傳到 now 為止錄製的節奏檔
+func (r *Recorder) Profile(now time.Time) *ActivityProfile {
+	r.profile.DurationMs = now.Sub(r.start).Milliseconds()
+	return r.profile
+}
+
+// RecordProfile 在 duration 內每 poll 取樣一次 idle，回傳錄製的節奏檔；stop 關閉時提早結束。
+// 取樣失敗會中止錄製並回傳錯誤。
+func RecordProfile(idle IdleSource, source string, duration, poll time.Duration, stop <-chan struct{}) (*ActivityProfile, error) {
+	start := time.Now()
+	r := NewRecorder(idle, source, start)
+	if err := r.Sample(start); err != nil {
+		return nil, fmt.Errorf("sample idle time failed: %w", err)
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-stop:
+			return r.Profile(time.Now()), nil
+		case <-deadline.C:
+			return r.Profile(time.Now()), nil
+		case now := <-ticker.C:
+			if err := r.Sample(now); err != nil {
+				return nil, fmt.Errorf("sample idle time failed: %w", err)
+			}
+		}
+	}
+}
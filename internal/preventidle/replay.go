@@ -0,0 +1,77 @@
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// ModeReplay 為依錄製的活動節奏檔隨機重播的模式
+const ModeReplay = "replay"
+
+// replay 模式的預設值
+const (
+	defaultReplayMaxGap    = 5 * time.Second
+	defaultReplayMaxEvents = 10
+)
+
+func init() {
+	RegisterMode(ModeReplay, Mode{Validate: validateReplay, Execute: replayActivity})
+}
+
+// validateReplay 確認 replay 模式設定了可讀取的節奏檔
+func validateReplay(cfg *config.IdlePreventionConfig) error {
+	if cfg.Replay.Profile == "" {
+		return errors.New("invalid idlePrevention.replay.profile: replay mode requires a profile recorded with the record command")
+	}
+	if _, err := LoadProfile(cfg.Replay.Profile); err != nil {
+		return fmt.Errorf("invalid idlePrevention.replay.profile: %w", err)
+	}
+	return nil
+}
+
+// replayActivity 依節奏檔隨機重播一段活動：每個動作的類型依鍵盤與滑鼠的使用比例抽樣，
+// 動作之間等待依間隔分布抽樣的時間；抽到超過 maxGap 的間隔或達到 maxEvents 即結束。
+// 節奏檔每次重新讀取，重新錄製後不需重啟。
+func replayActivity(injector InputInjector, cfg *config.IdlePreventionConfig, rng *rand.Rand) error {
+	p, err := LoadProfile(cfg.Replay.Profile)
+	if err != nil {
+		return err
+	}
+	maxGap := cfg.Replay.MaxGap
+	if maxGap <= 0 {
+		maxGap = defaultReplayMaxGap
+	}
+	maxEvents := cfg.Replay.MaxEvents
+	if maxEvents <= 0 {
+		maxEvents = defaultReplayMaxEvents
+	}
+
+	events := 0
+	for events < maxEvents {
+		if events > 0 {
+			gap := p.SampleGap(rng)
+			if gap > maxGap {
+				break
+			}
+			time.Sleep(gap)
+		}
+		a := configuredAction(cfg, p.SampleKind(rng))
+		if a.Visible() && !cfg.AllowVisible {
+			return fmt.Errorf("refuse to simulate %s: %w", a, ErrVisibleAction)
+		}
+		if a, err = resolvePattern(a, rng); err != nil {
+			return err
+		}
+		if err := injector.Perform(a); err != nil {
+			return fmt.Errorf("simulate %s failed: %w", a, err)
+		}
+		events++
+	}
+	logger.LogInfo(fmt.Sprintf("Replayed %d events from %s", events, cfg.Replay.Profile))
+	return nil
+}
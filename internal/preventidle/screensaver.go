@@ -0,0 +1,245 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// ScreenSaverBackendName 為透過 freedesktop ScreenSaver / GNOME SessionManager 抑制閒置的後端名稱
+const ScreenSaverBackendName = "screensaver"
+
+const (
+	screenSaverAppName = "GoIdleGuard"
+	screenSaverReason  = "GoIdleGuard active"
+
+	// gnomeInhibitFlags 為 Inhibit 的旗標：4 = 暫停、8 = 閒置
+	gnomeInhibitFlags = uint32(4 | 8)
+)
+
+// inhibitTarget 描述一個可透過 session bus 抑制閒置的服務
+type inhibitTarget struct {
+	service   string
+	path      dbus.ObjectPath
+	iface     string
+	uninhibit string
+	inhibit   func(obj dbus.BusObject) *dbus.Call
+}
+
+var screenSaverTargets = []inhibitTarget{
+	{
+		service:   "org.freedesktop.ScreenSaver",
+		path:      "/org/freedesktop/ScreenSaver",
+		iface:     "org.freedesktop.ScreenSaver",
+		uninhibit: "UnInhibit",
+		inhibit: func(obj dbus.BusObject) *dbus.Call {
+			return obj.Call("org.freedesktop.ScreenSaver.Inhibit", 0, screenSaverAppName, screenSaverReason)
+		},
+	},
+	{
+		service:   "org.gnome.SessionManager",
+		path:      "/org/gnome/SessionManager",
+		iface:     "org.gnome.SessionManager",
+		uninhibit: "Uninhibit",
+		inhibit: func(obj dbus.BusObject) *dbus.Call {
+			return obj.Call("org.gnome.SessionManager.Inhibit", 0, screenSaverAppName, uint32(0), screenSaverReason, gnomeInhibitFlags)
+		},
+	},
+}
+
+// ScreenSaverInhibitor 透過 org.freedesktop.ScreenSaver.Inhibit 與 org.gnome.SessionManager.Inhibit
+// 要求桌面環境不要進入閒置，並保存回傳的 cookie 以便解除。
+// 若服務重新啟動（舊 cookie 失效），會在持有期間自動重新抑制。
+type ScreenSaverInhibitor struct {
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	cookies map[string]uint32
+	holding bool
+	signals chan *dbus.Signal
+	done    chan struct{}
+}
+
+// OpenScreenSaverInhibitor 連線至 session bus，busAddress 為空時使用目前使用者的 session bus
+func OpenScreenSaverInhibitor(busAddress string) (*ScreenSaverInhibitor, error) {
+	var conn *dbus.Conn
+	var err error
+	if busAddress == "" {
+		conn, err = dbus.ConnectSessionBus()
+	} else {
+		conn, err = dbus.Connect(busAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to session bus failed: %w", err)
+	}
+
+	s := &ScreenSaverInhibitor{
+		conn:    conn,
+		cookies: map[string]uint32{},
+		signals: make(chan *dbus.Signal, 16),
+		done:    make(chan struct{}),
+	}
+	for _, t := range screenSaverTargets {
+		err := conn.AddMatchSignal(
+			dbus.WithMatchInterface("org.freedesktop.DBus"),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchArg(0, t.service),
+		)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("watch %s owner failed: %w", t.service, err)
+		}
+	}
+	conn.Signal(s.signals)
+	go s.watch()
+	return s, nil
+}
+
+// PreventSleep 向所有可用的服務要求抑制閒置；至少一個成功即視為成功。
+// 已持有時不重複抑制，避免覆蓋先前的 cookie 而無法解除；服務重新啟動後由 watch 重新抑制。
+func (s *ScreenSaverInhibitor) PreventSleep() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holding {
+		return nil
+	}
+
+	var errs []error
+	for _, t := range screenSaverTargets {
+		if err := s.inhibitLocked(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(s.cookies) == 0 {
+		return fmt.Errorf("screensaver inhibit failed: %w", errors.Join(errs...))
+	}
+	s.holding = true
+	return nil
+}
+
+// AllowIdle 以保存的 cookie 解除所有抑制
+func (s *ScreenSaverInhibitor) AllowIdle() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holding = false
+
+	var errs []error
+	for _, t := range screenSaverTargets {
+		cookie, ok := s.cookies[t.service]
+		if !ok {
+			continue
+		}
+		delete(s.cookies, t.service)
+		obj := s.conn.Object(t.service, t.path)
+		if err := obj.Call(t.iface+"."+t.uninhibit, 0, cookie).Err; err != nil {
+			errs = append(errs, fmt.Errorf("%s %s failed: %w", t.service, t.uninhibit, err))
+			continue
+		}
+		logger.LogInfo(fmt.Sprintf("Linux: %s inhibit released (cookie %d)", t.service, cookie))
+	}
+	return errors.Join(errs...)
+}
+
+// Cookies 回傳目前持有的 cookie（以服務名稱為鍵）
+func (s *ScreenSaverInhibitor) Cookies() map[string]uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]uint32, len(s.cookies))
+	for k, v := range s.cookies {
+		out[k] = v
+	}
+	return out
+}
+
+// Probe 確認至少有一個抑制服務在 session bus 上
+func (s *ScreenSaverInhibitor) Probe() error {
+	for _, t := range screenSaverTargets {
+		var has bool
+		err := s.conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, t.service).Store(&has)
+		if err == nil && has {
+			return nil
+		}
+	}
+	return errors.New("no ScreenSaver or SessionManager service on session bus")
+}
+
+// Close 解除抑制、停止監看並關閉連線
+func (s *ScreenSaverInhibitor) Close() error {
+	err := s.AllowIdle()
+	s.conn.RemoveSignal(s.signals)
+	close(s.done)
+	if cerr := s.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s *ScreenSaverInhibitor) inhibitLocked(t inhibitTarget) error {
+	var cookie uint32
+	if err := t.inhibit(s.conn.Object(t.service, t.path)).Store(&cookie); err != nil {
+		return fmt.Errorf("%s Inhibit failed: %w", t.service, err)
+	}
+	s.cookies[t.service] = cookie
+	logger.LogInfo(fmt.Sprintf("Linux: %s inhibit taken (cookie %d)", t.service, cookie))
+	return nil
+}
+
+// watch 監看服務擁有者變更：服務消失時丟棄失效的 cookie，重新出現且仍在持有期間時重新抑制
+func (s *ScreenSaverInhibitor) watch() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case sig, ok := <-s.signals:
+			if !ok {
+				return
+			}
+			if sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) != 3 {
+				continue
+			}
+			name, _ := sig.Body[0].(string)
+			newOwner, _ := sig.Body[2].(string)
+			s.ownerChanged(name, newOwner)
+		}
+	}
+}
+
+func (s *ScreenSaverInhibitor) ownerChanged(name, newOwner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range screenSaverTargets {
+		if t.service != name {
+			continue
+		}
+		delete(s.cookies, name)
+		if newOwner == "" || !s.holding {
+			return
+		}
+		logger.LogInfo("Linux: " + name + " restarted, re-inhibiting")
+		if err := s.inhibitLocked(t); err != nil {
+			logger.LogError("Linux: re-inhibit failed:", err)
+		}
+		return
+	}
+}
+
+func init() {
+	RegisterBackend(ScreenSaverBackendName, func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		s, err := OpenScreenSaverInhibitor(cfg.ScreenSaver.BusAddress)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: ScreenSaverBackendName, Power: s}, nil
+	})
+	RegisterCapabilities(ScreenSaverBackendName, func(cfg *config.IdlePreventionConfig) Capabilities {
+		return Capabilities{Power: cfg.ScreenSaver.BusAddress != "" || os.Getenv("DBUS_SESSION_BUS_ADDRESS") != ""}
+	})
+}
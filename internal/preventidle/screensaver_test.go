@@ -0,0 +1,200 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeInhibitService 為 ScreenSaver 與 GNOME SessionManager 共用的假服務
+type fakeInhibitService struct {
+	mu     sync.Mutex
+	next   uint32
+	active map[uint32]bool
+}
+
+func newFakeInhibitService(first uint32) *fakeInhibitService {
+	return &fakeInhibitService{next: first, active: map[uint32]bool{}}
+}
+
+func (f *fakeInhibitService) take() uint32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	f.active[f.next] = true
+	return f.next
+}
+
+func (f *fakeInhibitService) release(cookie uint32) *dbus.Error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.active[cookie] {
+		return dbus.MakeFailedError(dbus.ErrMsgNoObject)
+	}
+	delete(f.active, cookie)
+	return nil
+}
+
+func (f *fakeInhibitService) activeCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.active)
+}
+
+type fakeScreenSaver struct{ *fakeInhibitService }
+
+func (f fakeScreenSaver) Inhibit(app, reason string) (uint32, *dbus.Error) { return f.take(), nil }
+
+func (f fakeScreenSaver) UnInhibit(cookie uint32) *dbus.Error { return f.release(cookie) }
+
+type fakeSessionManager struct{ *fakeInhibitService }
+
+func (f fakeSessionManager) Inhibit(app string, xid uint32, reason string, flags uint32) (uint32, *dbus.Error) {
+	if flags&8 == 0 {
+		return 0, dbus.MakeFailedError(dbus.ErrMsgInvalidArg)
+	}
+	return f.take(), nil
+}
+
+func (f fakeSessionManager) Uninhibit(cookie uint32) *dbus.Error { return f.release(cookie) }
+
+func exportScreenSaver(t *testing.T, addr string, svc *fakeInhibitService) *dbus.Conn {
+	t.Helper()
+	return connectService(t, addr, "org.freedesktop.ScreenSaver", func(conn *dbus.Conn) error {
+		return conn.Export(fakeScreenSaver{svc}, "/org/freedesktop/ScreenSaver", "org.freedesktop.ScreenSaver")
+	})
+}
+
+func exportSessionManager(t *testing.T, addr string, svc *fakeInhibitService) {
+	t.Helper()
+	connectService(t, addr, "org.gnome.SessionManager", func(conn *dbus.Conn) error {
+		return conn.Export(fakeSessionManager{svc}, "/org/gnome/SessionManager", "org.gnome.SessionManager")
+	})
+}
+
+func TestScreenSaverInhibitor_InhibitAndRelease(t *testing.T) {
+	addr := startDBus(t)
+	ss := newFakeInhibitService(100)
+	gnome := newFakeInhibitService(200)
+	exportScreenSaver(t, addr, ss)
+	exportSessionManager(t, addr, gnome)
+
+	s, err := OpenScreenSaverInhibitor(addr)
+	if err != nil {
+		t.Fatalf("OpenScreenSaverInhibitor failed: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Probe(); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if err := s.PreventSleep(); err != nil {
+		t.Fatalf("PreventSleep failed: %v", err)
+	}
+	cookies := s.Cookies()
+	if cookies["org.freedesktop.ScreenSaver"] != 101 || cookies["org.gnome.SessionManager"] != 201 {
+		t.Errorf("Expected cookies 101 and 201, got %v", cookies)
+	}
+
+	if err := s.AllowIdle(); err != nil {
+		t.Fatalf("AllowIdle failed: %v", err)
+	}
+	if ss.activeCount() != 0 || gnome.activeCount() != 0 {
+		t.Errorf("Expected all inhibits released, got screensaver=%d gnome=%d", ss.activeCount(), gnome.activeCount())
+	}
+	if len(s.Cookies()) != 0 {
+		t.Errorf("Expected no cookies after AllowIdle, got %v", s.Cookies())
+	}
+}
+
+func TestScreenSaverInhibitor_RepeatedPreventSleep(t *testing.T) {
+	addr := startDBus(t)
+	ss := newFakeInhibitService(100)
+	gnome := newFakeInhibitService(200)
+	exportScreenSaver(t, addr, ss)
+	exportSessionManager(t, addr, gnome)
+
+	s, err := OpenScreenSaverInhibitor(addr)
+	if err != nil {
+		t.Fatalf("OpenScreenSaverInhibitor failed: %v", err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := s.PreventSleep(); err != nil {
+			t.Fatalf("PreventSleep %d failed: %v", i, err)
+		}
+	}
+	if ss.activeCount() != 1 || gnome.activeCount() != 1 {
+		t.Errorf("Expected a single inhibit per service, got screensaver=%d gnome=%d", ss.activeCount(), gnome.activeCount())
+	}
+	if err := s.AllowIdle(); err != nil {
+		t.Fatalf("AllowIdle failed: %v", err)
+	}
+	if ss.activeCount() != 0 || gnome.activeCount() != 0 {
+		t.Errorf("Expected all inhibits released, got screensaver=%d gnome=%d", ss.activeCount(), gnome.activeCount())
+	}
+}
+
+func TestScreenSaverInhibitor_PartialServices(t *testing.T) {
+	addr := startDBus(t)
+	gnome := newFakeInhibitService(0)
+	exportSessionManager(t, addr, gnome)
+
+	s, err := OpenScreenSaverInhibitor(addr)
+	if err != nil {
+		t.Fatalf("OpenScreenSaverInhibitor failed: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.PreventSleep(); err != nil {
+		t.Fatalf("Expected success with only SessionManager available, got %v", err)
+	}
+	if gnome.activeCount() != 1 {
+		t.Errorf("Expected one SessionManager inhibit, got %d", gnome.activeCount())
+	}
+}
+
+func TestScreenSaverInhibitor_ReinhibitsAfterServiceRestart(t *testing.T) {
+	addr := startDBus(t)
+	first := newFakeInhibitService(0)
+	conn := exportScreenSaver(t, addr, first)
+
+	s, err := OpenScreenSaverInhibitor(addr)
+	if err != nil {
+		t.Fatalf("OpenScreenSaverInhibitor failed: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.PreventSleep(); err != nil {
+		t.Fatalf("PreventSleep failed: %v", err)
+	}
+
+	// 模擬服務崩潰後重新啟動
+	conn.Close()
+	second := newFakeInhibitService(500)
+	exportScreenSaver(t, addr, second)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for second.activeCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if second.activeCount() != 1 {
+		t.Fatal("Expected re-inhibit on restarted ScreenSaver service")
+	}
+	if got := s.Cookies()["org.freedesktop.ScreenSaver"]; got != 501 {
+		t.Errorf("Expected new cookie 501, got %d", got)
+	}
+
+	if err := s.AllowIdle(); err != nil {
+		t.Fatalf("AllowIdle failed: %v", err)
+	}
+	if second.activeCount() != 0 {
+		t.Error("Expected restarted service inhibit released")
+	}
+}
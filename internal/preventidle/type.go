@@ -1,13 +1,28 @@
 package preventidle
 
+import "time"
+
 type IdleController struct {
 	StopChan chan struct{}
 	Running  bool
 }
 
+// SimulateAction 描述一個模擬輸入動作：
+// Type 為 "key" 時按下並放開 Key；為 "mouse" 時依序播放 Trajectory() 的相對位移，
+// 預設為 (DX, DY) 出去再回來，或由 Pattern 產生的 Moves，步與步之間等待 StepDelay；
+// 為 "click" 時在目前游標位置點擊 Button；為 "scroll" 時捲動滾輪 Scroll 格（正值向上）
 type SimulateAction struct {
-	inputType  string
-	actionName string
+	Type      string
+	Key       string
+	DX        int32
+	DY        int32
+	Button    string
+	Scroll    int32
+	Pattern   string
+	Radius    int32
+	Steps     int
+	StepDelay time.Duration
+	Moves     []Move
 }
 
 type LastInputInfo struct {
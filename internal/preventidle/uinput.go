@@ -0,0 +1,300 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/keys"
+)
+
+// Linux input 事件類型與代碼（見 linux/input-event-codes.h）
+const (
+	evSyn = 0x00
+	evKey = 0x01
+	evRel = 0x02
+	evAbs = 0x03
+
+	synReport = 0
+
+	relX     = 0x00
+	relY     = 0x01
+	relWheel = 0x08
+
+	keyLeftShift = 42
+	keyMax       = 0xff
+
+	btnMisc   = 0x100 // 此值以上為滑鼠、搖桿等裝置的按鍵（BTN_*）
+	btnLeft   = 0x110
+	btnRight  = 0x111
+	btnMiddle = 0x112
+)
+
+// uinput ioctl 請求碼（見 linux/uinput.h）
+const (
+	uiDevCreate  = 0x5501
+	uiDevDestroy = 0x5502
+	uiDevSetup   = 0x405c5503
+	uiSetEvBit   = 0x40045564
+	uiSetKeyBit  = 0x40045565
+	uiSetRelBit  = 0x40045566
+
+	busVirtual = 0x06
+)
+
+// UinputBackendName 為以 uinput 虛擬裝置注入輸入的後端名稱
+const UinputBackendName = "uinput"
+
+// UinputDeviceName 為建立的虛擬輸入裝置名稱，供 evdev 等來源辨識並排除自身事件
+const UinputDeviceName = "goidleguard-virtual-input"
+
+// timevalSize 為目前平台 struct timeval 的位元組大小
+var timevalSize = int(unsafe.Sizeof(syscall.Timeval{}))
+
+// uinputSetup 對應 struct uinput_setup
+type uinputSetup struct {
+	ID struct {
+		Bustype uint16
+		Vendor  uint16
+		Product uint16
+		Version uint16
+	}
+	Name         [80]byte
+	FFEffectsMax uint32
+}
+
+// UinputDevice 透過 /dev/uinput 建立虛擬鍵盤／滑鼠，並寫入核心 input_event。
+// 若 Path 指向的不是字元裝置（例如測試用的管線或一般檔案），則略過 ioctl 設定，只寫入事件。
+type UinputDevice struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+	real bool
+}
+
+// OpenUinputDevice 開啟指定路徑並建立虛擬輸入裝置
+func OpenUinputDevice(path string) (*UinputDevice, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open uinput device %s failed: %w", path, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("stat uinput device %s failed: %w", path, err)
+	}
+
+	d := &UinputDevice{Path: path, file: f, real: fi.Mode()&os.ModeCharDevice != 0}
+	if d.real {
+		if err := d.setup(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// setup 宣告支援的事件類型並建立虛擬裝置
+func (d *UinputDevice) setup() error {
+	if err := d.ioctl(uiSetEvBit, evKey); err != nil {
+		return fmt.Errorf("UI_SET_EVBIT EV_KEY failed: %w", err)
+	}
+	for code := uintptr(1); code <= keyMax; code++ {
+		if err := d.ioctl(uiSetKeyBit, code); err != nil {
+			return fmt.Errorf("UI_SET_KEYBIT %d failed: %w", code, err)
+		}
+	}
+	for _, code := range []uintptr{btnLeft, btnRight, btnMiddle} {
+		if err := d.ioctl(uiSetKeyBit, code); err != nil {
+			return fmt.Errorf("UI_SET_KEYBIT %d failed: %w", code, err)
+		}
+	}
+	if err := d.ioctl(uiSetEvBit, evRel); err != nil {
+		return fmt.Errorf("UI_SET_EVBIT EV_REL failed: %w", err)
+	}
+	for _, code := range []uintptr{relX, relY, relWheel} {
+		if err := d.ioctl(uiSetRelBit, code); err != nil {
+			return fmt.Errorf("UI_SET_RELBIT %d failed: %w", code, err)
+		}
+	}
+
+	var us uinputSetup
+	us.ID.Bustype = busVirtual
+	us.ID.Vendor = 0x1
+	us.ID.Product = 0x1
+	us.ID.Version = 1
+	copy(us.Name[:], UinputDeviceName)
+	if err := d.ioctl(uiDevSetup, uintptr(unsafe.Pointer(&us))); err != nil {
+		return fmt.Errorf("UI_DEV_SETUP failed: %w", err)
+	}
+	if err := d.ioctl(uiDevCreate, 0); err != nil {
+		return fmt.Errorf("UI_DEV_CREATE failed: %w", err)
+	}
+	// 給桌面環境一點時間偵測新裝置，否則第一批事件可能被忽略
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
+
+func (d *UinputDevice) ioctl(req, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.file.Fd(), req, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// SendInput 實作 InputInjector：
+// "key" 按下並放開 Shift 鍵，"mouse" 向右平移 1 像素後再移回原位
+func (d *UinputDevice) SendInput(inputType string) error {
+	action, err := DefaultAction(inputType)
+	if err != nil {
+		return fmt.Errorf("uinput: %w", err)
+	}
+	return d.Perform(action)
+}
+
+// Perform 實作 InputInjector，送出設定的按鍵、滑鼠相對位移軌跡或點擊
+func (d *UinputDevice) Perform(action SimulateAction) error {
+	switch action.Type {
+	case ActionKey:
+		k, err := keys.Lookup(action.Key)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < k.Taps(); i++ {
+			if err := d.KeyTap(k.Linux); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ActionMouse:
+		return playMoves(action, d.MoveRelative)
+	case ActionClick:
+		button, err := action.button()
+		if err != nil {
+			return err
+		}
+		return d.KeyTap(map[string]uint16{"left": btnLeft, "right": btnRight, "middle": btnMiddle}[button])
+	case ActionScroll:
+		return d.Scroll(action.Scroll)
+	default:
+		return fmt.Errorf("unsupported action type for uinput: %s", action.Type)
+	}
+}
+
+// KeyTap 送出指定按鍵的按下與放開事件
+func (d *UinputDevice) KeyTap(code uint16) error {
+	var buf bytes.Buffer
+	writeInputEvent(&buf, evKey, code, 1)
+	writeInputEvent(&buf, evSyn, synReport, 0)
+	writeInputEvent(&buf, evKey, code, 0)
+	writeInputEvent(&buf, evSyn, synReport, 0)
+	return d.write(buf.Bytes())
+}
+
+// MoveRelative 送出相對滑鼠位移事件
+func (d *UinputDevice) MoveRelative(dx, dy int32) error {
+	var buf bytes.Buffer
+	if dx != 0 {
+		writeInputEvent(&buf, evRel, relX, dx)
+	}
+	if dy != 0 {
+		writeInputEvent(&buf, evRel, relY, dy)
+	}
+	writeInputEvent(&buf, evSyn, synReport, 0)
+	return d.write(buf.Bytes())
+}
+
+// Scroll 送出滾輪事件，正值向上
+func (d *UinputDevice) Scroll(notches int32) error {
+	var buf bytes.Buffer
+	writeInputEvent(&buf, evRel, relWheel, notches)
+	writeInputEvent(&buf, evSyn, synReport, 0)
+	return d.write(buf.Bytes())
+}
+
+// Probe 確認裝置仍處於開啟狀態
+func (d *UinputDevice) Probe() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return fmt.Errorf("uinput device %s is closed", d.Path)
+	}
+	return nil
+}
+
+func (d *UinputDevice) write(p []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return fmt.Errorf("uinput device %s is closed", d.Path)
+	}
+	if _, err := d.file.Write(p); err != nil {
+		return fmt.Errorf("write uinput events failed: %w", err)
+	}
+	return nil
+}
+
+// Close 銷毀虛擬裝置並關閉檔案
+func (d *UinputDevice) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return nil
+	}
+	if d.real {
+		_ = d.ioctl(uiDevDestroy, 0)
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+// writeInputEvent 依 struct input_event 的記憶體佈局寫入一筆事件。
+// 時間欄位保持為零，由核心於注入時填入實際時間。
+func writeInputEvent(buf *bytes.Buffer, typ, code uint16, value int32) {
+	buf.Write(make([]byte, timevalSize))
+	binary.Write(buf, binary.NativeEndian, typ)
+	binary.Write(buf, binary.NativeEndian, code)
+	binary.Write(buf, binary.NativeEndian, value)
+}
+
+// accessWrite 為 access(2) 的 W_OK
+const accessWrite = 0x2
+
+// uinputWritable 以 access(2) 確認目前使用者可寫入 path，不開啟裝置
+func uinputWritable(path string) bool {
+	return syscall.Access(path, accessWrite) == nil
+}
+
+// uinputPath 回傳設定的 uinput 裝置路徑，未設定時為 UinputDevicePath
+func uinputPath(cfg *config.IdlePreventionConfig) string {
+	if cfg.Uinput.Device != "" {
+		return cfg.Uinput.Device
+	}
+	return UinputDevicePath
+}
+
+func init() {
+	RegisterBackend(UinputBackendName, func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		path := uinputPath(cfg)
+		dev, err := OpenUinputDevice(path)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: UinputBackendName, Injector: dev}, nil
+	})
+	RegisterCapabilities(UinputBackendName, func(cfg *config.IdlePreventionConfig) Capabilities {
+		return Capabilities{Input: uinputWritable(uinputPath(cfg))}
+	})
+}
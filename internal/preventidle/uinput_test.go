@@ -0,0 +1,237 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+type rawEvent struct {
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// decodeEvents 依 struct input_event 佈局解析寫入的事件，並確認時間欄位為零
+func decodeEvents(t *testing.T, data []byte) []rawEvent {
+	t.Helper()
+	size := timevalSize + 8
+	if len(data)%size != 0 {
+		t.Fatalf("Expected a multiple of %d bytes, got %d", size, len(data))
+	}
+	var events []rawEvent
+	for off := 0; off < len(data); off += size {
+		if !bytes.Equal(data[off:off+timevalSize], make([]byte, timevalSize)) {
+			t.Errorf("Expected zero timeval at offset %d", off)
+		}
+		var ev rawEvent
+		if err := binary.Read(bytes.NewReader(data[off+timevalSize:off+size]), binary.NativeEndian, &ev); err != nil {
+			t.Fatalf("Failed to decode event: %v", err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestUinputDevice_KeyTapWritesEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uinput")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Failed to create fake device: %v", err)
+	}
+
+	dev, err := OpenUinputDevice(path)
+	if err != nil {
+		t.Fatalf("OpenUinputDevice failed: %v", err)
+	}
+	if err := dev.KeyTap(keyLeftShift); err != nil {
+		t.Fatalf("KeyTap failed: %v", err)
+	}
+	dev.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fake device: %v", err)
+	}
+	got := decodeEvents(t, data)
+	expected := []rawEvent{
+		{evKey, keyLeftShift, 1},
+		{evSyn, synReport, 0},
+		{evKey, keyLeftShift, 0},
+		{evSyn, synReport, 0},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestCallSendInput_MouseThroughPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uinput.fifo")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skipf("mkfifo not supported: %v", err)
+	}
+
+	received := make(chan []byte, 1)
+	go func() {
+		f, err := os.Open(path)
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer f.Close()
+		data, _ := io.ReadAll(f)
+		received <- data
+	}()
+
+	orig := UinputDevicePath
+	UinputDevicePath = path
+	defer func() { UinputDevicePath = orig }()
+
+	if err := CallSendInput("mouse"); err != nil {
+		t.Fatalf("CallSendInput failed: %v", err)
+	}
+	uinputMu.Lock()
+	uinputDev.Close()
+	uinputDev = nil
+	uinputMu.Unlock()
+
+	got := decodeEvents(t, <-received)
+	expected := []rawEvent{
+		{evRel, relX, 1},
+		{evSyn, synReport, 0},
+		{evRel, relX, -1},
+		{evSyn, synReport, 0},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestUinputDevice_PerformConfiguredActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uinput")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Failed to create fake device: %v", err)
+	}
+
+	dev, err := OpenUinputDevice(path)
+	if err != nil {
+		t.Fatalf("OpenUinputDevice failed: %v", err)
+	}
+	for _, a := range []SimulateAction{
+		{Type: ActionKey, Key: "f15"},
+		{Type: ActionMouse, DX: 3, DY: -2},
+		{Type: ActionClick, Button: "right"},
+		{Type: ActionScroll, Scroll: -2},
+	} {
+		if err := dev.Perform(a); err != nil {
+			t.Fatalf("Perform %s failed: %v", a, err)
+		}
+	}
+	dev.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fake device: %v", err)
+	}
+	got := decodeEvents(t, data)
+	expected := []rawEvent{
+		{evKey, 185, 1},
+		{evSyn, synReport, 0},
+		{evKey, 185, 0},
+		{evSyn, synReport, 0},
+		{evRel, relX, 3},
+		{evRel, relY, -2},
+		{evSyn, synReport, 0},
+		{evRel, relX, -3},
+		{evRel, relY, 2},
+		{evSyn, synReport, 0},
+		{evKey, btnRight, 1},
+		{evSyn, synReport, 0},
+		{evKey, btnRight, 0},
+		{evSyn, synReport, 0},
+		{evRel, relWheel, -2},
+		{evSyn, synReport, 0},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestProbeNativeInput_DoesNotCreateDevice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uinput")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Failed to create fake device: %v", err)
+	}
+	orig := UinputDevicePath
+	UinputDevicePath = path
+	defer func() { UinputDevicePath = orig }()
+
+	if err := probeNativeInput(); err != nil {
+		t.Fatalf("probeNativeInput failed: %v", err)
+	}
+	uinputMu.Lock()
+	created := uinputDev != nil
+	uinputMu.Unlock()
+	if created {
+		t.Error("Expected probe not to create the shared uinput device")
+	}
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Errorf("Expected probe to write nothing, got %d bytes", len(data))
+	}
+
+	UinputDevicePath = filepath.Join(t.TempDir(), "missing")
+	if err := probeNativeInput(); err == nil {
+		t.Error("Expected error for missing device, got nil")
+	}
+}
+
+func TestUinputDevice_LockKeyTappedTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uinput")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Failed to create fake device: %v", err)
+	}
+
+	dev, err := OpenUinputDevice(path)
+	if err != nil {
+		t.Fatalf("OpenUinputDevice failed: %v", err)
+	}
+	if err := dev.Perform(SimulateAction{Type: ActionKey, Key: "ScrollLock"}); err != nil {
+		t.Fatalf("Perform failed: %v", err)
+	}
+	dev.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fake device: %v", err)
+	}
+	presses := 0
+	for _, e := range decodeEvents(t, data) {
+		if e.Type == evKey && e.Code == 70 && e.Value == 1 {
+			presses++
+		}
+	}
+	if presses != 2 {
+		t.Errorf("Expected ScrollLock pressed twice to restore lock state, got %d", presses)
+	}
+}
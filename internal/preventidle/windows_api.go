@@ -9,6 +9,7 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/HanksJCTsai/goidleguard/internal/keys"
 	"github.com/HanksJCTsai/goidleguard/pkg/logger"
 )
 
@@ -33,8 +34,18 @@ const (
 	// keyboard event flags
 	KEYEVENTF_KEYUP = 0x0002
 
-	// virtual key codes
-	VK_SPACE = 0x20
+	// mouse event flags
+	MOUSEEVENTF_MOVE       = 0x0001
+	MOUSEEVENTF_LEFTDOWN   = 0x0002
+	MOUSEEVENTF_LEFTUP     = 0x0004
+	MOUSEEVENTF_RIGHTDOWN  = 0x0008
+	MOUSEEVENTF_RIGHTUP    = 0x0010
+	MOUSEEVENTF_MIDDLEDOWN = 0x0020
+	MOUSEEVENTF_MIDDLEUP   = 0x0040
+	MOUSEEVENTF_WHEEL      = 0x0800
+
+	// WHEEL_DELTA 為滾輪一格的量
+	WHEEL_DELTA = 120
 )
 
 // PreventSleep 建立「PreventUserIdleSystemSleep」宣告
@@ -58,73 +69,121 @@ func AllowIdle() error {
 	return nil
 }
 
-// CallSendInput 使用 Core Graphics API 模擬鍵盤或滑鼠事件。
-// 當 mode 為 "key" 時，以 Shift 鍵 (key code 56) 為示例產生鍵盤按下與釋放事件；
-// 當 mode 為 "mouse" 時，取得目前滑鼠位置，並向右平移 1 像素後建立滑鼠移動事件
+// CallSendInput 使用 SendInput 模擬鍵盤或滑鼠事件。
+// 當 mode 為 "key" 時，按下並放開 Shift 鍵；
+// 當 mode 為 "mouse" 時，向右平移 1 像素後再移回原位
 func CallSendInput(mode string) error {
-	switch mode {
-	case "key":
-		// key down
-		ki := CallKeyboardInput{
-			Type: INPUT_KEYBOARD,
-			Ki: KeyboardInput{
-				WVk:         VK_SPACE,
-				WScan:       0,
-				DwFlags:     0,
-				Time:        0,
-				DwExtraInfo: 0,
-			},
+	action, err := DefaultAction(mode)
+	if err != nil {
+		return fmt.Errorf("unsupported mode for CallSendInput on Windows: %s", mode)
+	}
+	return CallPerform(action)
+}
+
+// CallPerform 使用 SendInput 送出設定的按鍵、滑鼠相對位移軌跡或點擊
+func CallPerform(action SimulateAction) error {
+	switch action.Type {
+	case ActionKey:
+		k, err := keys.Lookup(action.Key)
+		if err != nil {
+			return err
 		}
-		n, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&ki)), unsafe.Sizeof(ki))
-		if n == 0 {
-			return fmt.Errorf("SendInput key down failed: %v", err)
+		if k.Windows == 0 {
+			return fmt.Errorf("key %s is not supported on Windows", action.Key)
 		}
-		// key up
-		ki.Ki.DwFlags = KEYEVENTF_KEYUP
-		n, _, err = procSendInput.Call(1, uintptr(unsafe.Pointer(&ki)), unsafe.Sizeof(ki))
-		if n == 0 {
-			return fmt.Errorf("SendInput key up failed: %v", err)
+		for i := 0; i < k.Taps(); i++ {
+			// key down
+			ki := CallKeyboardInput{
+				Type: INPUT_KEYBOARD,
+				Ki: KeyboardInput{
+					WVk:         k.Windows,
+					WScan:       0,
+					DwFlags:     0,
+					Time:        0,
+					DwExtraInfo: 0,
+				},
+			}
+			n, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&ki)), unsafe.Sizeof(ki))
+			if n == 0 {
+				return fmt.Errorf("SendInput key down failed: %v", err)
+			}
+			// key up
+			ki.Ki.DwFlags = KEYEVENTF_KEYUP
+			n, _, err = procSendInput.Call(1, uintptr(unsafe.Pointer(&ki)), unsafe.Sizeof(ki))
+			if n == 0 {
+				return fmt.Errorf("SendInput key up failed: %v", err)
+			}
 		}
-		logger.LogInfo("Windows: simulated key press (space)")
+		logger.LogInfo("Windows: simulated key press (" + action.Key + ")")
 		return nil
 
-	case "mouse":
-		// get current cursor position and move by +1 X
-		// here we simply send relative move
-		mi := CallMouseInput{
-			Type: INPUT_MOUSE,
-			Mi: MouseInput{
-				dx:          10,
-				dy:          0,
-				dwFlags:     0x0001, // MOUSEEVENTF_MOVE
-				dwExtraInfo: 0,
-			},
+	case ActionMouse:
+		err := playMoves(action, func(dx, dy int32) error {
+			if err := sendMouseInput(dx, dy, MOUSEEVENTF_MOVE); err != nil {
+				return fmt.Errorf("SendInput mouse move failed: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-		n, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&mi)), unsafe.Sizeof(mi))
-		if n == 0 {
-			return fmt.Errorf("SendInput mouse move failed: %v", err)
+		logger.LogInfo("Windows: simulated mouse move")
+		return nil
+
+	case ActionClick:
+		button, err := action.button()
+		if err != nil {
+			return err
 		}
+		flags := map[string][2]uint32{
+			"left":   {MOUSEEVENTF_LEFTDOWN, MOUSEEVENTF_LEFTUP},
+			"right":  {MOUSEEVENTF_RIGHTDOWN, MOUSEEVENTF_RIGHTUP},
+			"middle": {MOUSEEVENTF_MIDDLEDOWN, MOUSEEVENTF_MIDDLEUP},
+		}[button]
+		for _, f := range flags {
+			if err := sendMouseInput(0, 0, f); err != nil {
+				return fmt.Errorf("SendInput mouse click failed: %v", err)
+			}
+		}
+		logger.LogInfo("Windows: simulated " + button + " click")
+		return nil
 
-		mi = CallMouseInput{
+	case ActionScroll:
+		mi := CallMouseInput{
 			Type: INPUT_MOUSE,
 			Mi: MouseInput{
-				dx:          10,
-				dy:          0,
-				dwFlags:     0x0001, // MOUSEEVENTF_MOVE
-				dwExtraInfo: 0,
+				mouseData: uint32(action.Scroll * WHEEL_DELTA),
+				dwFlags:   MOUSEEVENTF_WHEEL,
 			},
 		}
-		n, _, err = procSendInput.Call(1, uintptr(unsafe.Pointer(&mi)), unsafe.Sizeof(mi))
+		n, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&mi)), unsafe.Sizeof(mi))
 		if n == 0 {
-			return fmt.Errorf("SendInput mouse move failed: %v", err)
+			return fmt.Errorf("SendInput mouse wheel failed: %v", err)
 		}
-
-		logger.LogInfo("Windows: simulated mouse move")
+		logger.LogInfo(fmt.Sprintf("Windows: simulated scroll (%d)", action.Scroll))
 		return nil
 
 	default:
-		return fmt.Errorf("unsupported mode for CallSendInput on Windows: %s", mode)
+		return fmt.Errorf("unsupported action type for CallPerform on Windows: %s", action.Type)
+	}
+}
+
+// sendMouseInput 送出一筆滑鼠 INPUT
+func sendMouseInput(dx, dy int32, flags uint32) error {
+	mi := CallMouseInput{
+		Type: INPUT_MOUSE,
+		Mi: MouseInput{
+			dx:          dx,
+			dy:          dy,
+			dwFlags:     flags,
+			dwExtraInfo: 0,
+		},
 	}
+	n, _, err := procSendInput.Call(1, uintptr(unsafe.Pointer(&mi)), unsafe.Sizeof(mi))
+	if n == 0 {
+		return err
+	}
+	return nil
 }
 
 // GetIdleTime 使用 CGEventSourceSecondsSinceLastEventType 取得系統閒置時間（以秒計），並轉換為 time.Duration。
@@ -148,3 +207,12 @@ func GetIdleTime() (time.Duration, error) {
 	idleMs := uptimeMs - li.dwTime
 	return time.Duration(idleMs) * time.Millisecond, nil
 }
+
+// probeNativeInput、checkNativeInput、probeNativeIdle 與 probeNativePower 在 Windows 上一律可用
+func probeNativeInput() error { return nil }
+
+func checkNativeInput() error { return nil }
+
+func probeNativeIdle() error { return nil }
+
+func probeNativePower() error { return nil }
@@ -0,0 +1,224 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+
+	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/keys"
+)
+
+// XTestBackendName 為透過 X11 XTEST 與 MIT-SCREEN-SAVER 擴充的後端名稱
+const XTestBackendName = "xtest"
+
+// keysymShiftL 為 Shift_L 的 X keysym
+const keysymShiftL = 0xffe1
+
+// X11Session 以純 Go 實作的 X 協定連線（不需 cgo）：
+// 透過 XTEST FakeInput 注入輸入，並以 MIT-SCREEN-SAVER QueryInfo 讀取伺服器的閒置計數器。
+type X11Session struct {
+	mu       sync.Mutex
+	conn     *xgb.Conn
+	root     xproto.Window
+	keycodes map[xproto.Keysym]xproto.Keycode
+}
+
+// OpenX11Session 連線至指定的 X display，空字串代表使用 $DISPLAY
+func OpenX11Session(display string) (*X11Session, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("connect to X display %q failed: %w", display, err)
+	}
+	if err := xtest.Init(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("XTEST extension unavailable: %w", err)
+	}
+	if err := screensaver.Init(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("MIT-SCREEN-SAVER extension unavailable: %w", err)
+	}
+
+	x := &X11Session{
+		conn:     conn,
+		root:     xproto.Setup(conn).DefaultScreen(conn).Root,
+		keycodes: map[xproto.Keysym]xproto.Keycode{},
+	}
+	if _, err = x.keycodeFor(keysymShiftL); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+// keycodeFor 從伺服器的鍵盤對應表找出 keysym 所對應的 keycode，結果會快取
+func (x *X11Session) keycodeFor(keysym xproto.Keysym) (xproto.Keycode, error) {
+	if code, ok := x.keycodes[keysym]; ok {
+		return code, nil
+	}
+	setup := xproto.Setup(x.conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	reply, err := xproto.GetKeyboardMapping(x.conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("GetKeyboardMapping failed: %w", err)
+	}
+	per := int(reply.KeysymsPerKeycode)
+	for i, sym := range reply.Keysyms {
+		if sym == keysym {
+			code := setup.MinKeycode + xproto.Keycode(i/per)
+			x.keycodes[keysym] = code
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("no keycode mapped to keysym 0x%x", keysym)
+}
+
+// SendInput 實作 InputInjector：
+// "key" 按下並放開 Shift 鍵，"mouse" 以相對移動向右 1 像素後再移回原位
+func (x *X11Session) SendInput(inputType string) error {
+	action, err := DefaultAction(inputType)
+	if err != nil {
+		return fmt.Errorf("xtest: %w", err)
+	}
+	return x.Perform(action)
+}
+
+// Perform 實作 InputInjector，送出設定的按鍵、滑鼠相對位移軌跡或點擊
+func (x *X11Session) Perform(action SimulateAction) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.conn == nil {
+		return errors.New("X11 session is closed")
+	}
+
+	switch action.Type {
+	case ActionKey:
+		k, err := keys.Lookup(action.Key)
+		if err != nil {
+			return err
+		}
+		code, err := x.keycodeFor(xproto.Keysym(k.X11))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < k.Taps(); i++ {
+			if err := x.fakeInput(xproto.KeyPress, byte(code), 0, 0); err != nil {
+				return err
+			}
+			if err := x.fakeInput(xproto.KeyRelease, byte(code), 0, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ActionMouse:
+		return playMoves(action, func(dx, dy int32) error {
+			return x.fakeInput(xproto.MotionNotify, 1, int16(dx), int16(dy))
+		})
+	case ActionClick:
+		button, err := action.button()
+		if err != nil {
+			return err
+		}
+		detail := map[string]byte{"left": 1, "middle": 2, "right": 3}[button]
+		if err := x.fakeInput(xproto.ButtonPress, detail, 0, 0); err != nil {
+			return err
+		}
+		return x.fakeInput(xproto.ButtonRelease, detail, 0, 0)
+	case ActionScroll:
+		// X11 以按鍵 4（向上）與 5（向下）表示滾輪，每格一次按下與放開
+		detail, n := byte(4), action.Scroll
+		if n < 0 {
+			detail, n = 5, -n
+		}
+		for i := int32(0); i < n; i++ {
+			if err := x.fakeInput(xproto.ButtonPress, detail, 0, 0); err != nil {
+				return err
+			}
+			if err := x.fakeInput(xproto.ButtonRelease, detail, 0, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported action type for xtest: %s", action.Type)
+	}
+}
+
+// fakeInput 送出一筆 XTEST FakeInput 並等待伺服器確認。
+// MotionNotify 的 detail 為 1 時，x、y 代表相對位移。
+func (x *X11Session) fakeInput(eventType, detail byte, dx, dy int16) error {
+	if err := xtest.FakeInputChecked(x.conn, eventType, detail, 0, xproto.WindowNone, dx, dy, 0).Check(); err != nil {
+		return fmt.Errorf("XTEST FakeInput failed: %w", err)
+	}
+	return nil
+}
+
+// IdleTime 實作 IdleSource，回傳 X 伺服器自上次使用者輸入以來的時間
+func (x *X11Session) IdleTime() (time.Duration, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.conn == nil {
+		return 0, errors.New("X11 session is closed")
+	}
+
+	info, err := screensaver.QueryInfo(x.conn, xproto.Drawable(x.root)).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("MIT-SCREEN-SAVER QueryInfo failed: %w", err)
+	}
+	return time.Duration(info.MsSinceUserInput) * time.Millisecond, nil
+}
+
+// Probe 以一次往返請求確認連線仍然有效
+func (x *X11Session) Probe() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.conn == nil {
+		return errors.New("X11 session is closed")
+	}
+	if _, err := xproto.GetInputFocus(x.conn).Reply(); err != nil {
+		return fmt.Errorf("X server not responding: %w", err)
+	}
+	return nil
+}
+
+// Close 關閉 X 連線
+func (x *X11Session) Close() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.conn != nil {
+		x.conn.Close()
+		x.conn = nil
+	}
+}
+
+func init() {
+	RegisterBackend(XTestBackendName, func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		x, err := OpenX11Session(cfg.X11.Display)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: XTestBackendName, Injector: x, Idle: x}, nil
+	})
+	RegisterIdleSource(XTestBackendName, func(cfg *config.IdlePreventionConfig) (*Backend, error) {
+		x, err := OpenX11Session(cfg.X11.Display)
+		if err != nil {
+			return nil, err
+		}
+		return &Backend{Name: XTestBackendName, Idle: x}, nil
+	})
+	// 有指定或可由 $DISPLAY 取得 X display 時，視為提供輸入注入與閒置時間
+	RegisterCapabilities(XTestBackendName, func(cfg *config.IdlePreventionConfig) Capabilities {
+		hasDisplay := cfg.X11.Display != "" || os.Getenv("DISPLAY") != ""
+		return Capabilities{Input: hasDisplay, Idle: hasDisplay}
+	})
+}
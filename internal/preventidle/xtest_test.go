@@ -0,0 +1,98 @@
+//go:build linux
+// +build linux
+
+package preventidle
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startXvfb 啟動一個私有的 Xvfb 並回傳其 display；未安裝 Xvfb 時略過測試
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("Xvfb")
+	if err != nil {
+		t.Skip("Xvfb not installed")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+
+	cmd := exec.Command(path, "-displayfd", "3", "-nolisten", "tcp", "-screen", "0", "640x480x24")
+	cmd.ExtraFiles = []*os.File{w}
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		t.Fatalf("Failed to start Xvfb: %v", err)
+	}
+	w.Close()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(r).ReadString('\n')
+		line <- strings.TrimSpace(s)
+	}()
+	select {
+	case num := <-line:
+		if num == "" {
+			t.Fatal("Xvfb did not report a display number")
+		}
+		return ":" + num
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for Xvfb")
+	}
+	return ""
+}
+
+func TestX11Session_InjectionResetsIdleCounter(t *testing.T) {
+	display := startXvfb(t)
+
+	x, err := OpenX11Session(display)
+	if err != nil {
+		t.Fatalf("OpenX11Session failed: %v", err)
+	}
+	defer x.Close()
+
+	if err := x.Probe(); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	for _, inputType := range []string{"key", "mouse"} {
+		time.Sleep(1200 * time.Millisecond)
+		before, err := x.IdleTime()
+		if err != nil {
+			t.Fatalf("IdleTime failed: %v", err)
+		}
+		if before < time.Second {
+			t.Fatalf("Expected idle >= 1s before %s injection, got %v", inputType, before)
+		}
+
+		if err := x.SendInput(inputType); err != nil {
+			t.Fatalf("SendInput(%s) failed: %v", inputType, err)
+		}
+		after, err := x.IdleTime()
+		if err != nil {
+			t.Fatalf("IdleTime failed: %v", err)
+		}
+		if after >= before || after > 500*time.Millisecond {
+			t.Errorf("Expected %s injection to reset idle counter, before=%v after=%v", inputType, before, after)
+		}
+	}
+}
+
+func TestX11Session_UnknownDisplay(t *testing.T) {
+	if _, err := OpenX11Session(":4242"); err == nil {
+		t.Error("Expected error connecting to a missing display, got nil")
+	}
+}
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HanksJCTsai/goidleguard/internal/ical"
+	"github.com/HanksJCTsai/goidleguard/pkg/logger"
+)
+
+// HolidayCalendar 由本機 .ics 檔案判斷非工作時間；每次檢查前比對檔案的修改時間與大小，
+// 變更時重新解析。重新解析失敗時沿用上一次成功載入的內容。
+type HolidayCalendar struct {
+	mu      sync.Mutex
+	files   []*holidayFile
+	current string // 目前所在的事件名稱，只在進入與離開時記錄
+}
+
+type holidayFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+	cal     *ical.Calendar
+	err     string // 最近一次載入失敗的訊息，用來避免重複記錄同一個錯誤
+}
+
+// NewHolidayCalendar 以 .ics 檔案路徑建立 HolidayCalendar，檔案在第一次檢查時載入
+func NewHolidayCalendar(paths []string) *HolidayCalendar {
+	h := &HolidayCalendar{}
+	for _, p := range paths {
+		h.files = append(h.files, &holidayFile{path: p})
+	}
+	return h
+}
+
+// Holiday 回報 now 是否落在任一行事曆事件期間內，並回傳事件名稱。
+// 全天事件與沒有時區的時刻依 now 的時區解讀。
+func (h *HolidayCalendar) Holiday(now time.Time) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	name, ok := h.eventAt(now)
+	if name != h.current {
+		if ok {
+			logger.LogInfo(fmt.Sprintf("Holiday calendar: %q, treating as non-working time", name))
+		} else {
+			logger.LogInfo(fmt.Sprintf("Holiday calendar: %q ended", h.current))
+		}
+		h.current = name
+	}
+	return name, ok
+}
+
+// eventAt 重新載入變更過的檔案後，回傳 now 時進行中的第一個事件名稱
+func (h *HolidayCalendar) eventAt(now time.Time) (string, bool) {
+	for _, f := range h.files {
+		f.reload()
+		if f.cal == nil {
+			continue
+		}
+		if e, ok := f.cal.EventAt(now); ok {
+			name := e.Summary
+			if name == "" {
+				name = e.UID
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// reload 在檔案的修改時間或大小改變時重新解析
+func (f *holidayFile) reload() {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		f.fail(err)
+		return
+	}
+	loaded := f.cal != nil || f.err != ""
+	if loaded && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return
+	}
+	f.modTime, f.size = info.ModTime(), info.Size()
+
+	cal, err := ical.ParseFile(f.path)
+	if err != nil {
+		f.fail(err)
+		return
+	}
+	action := "loaded"
+	if f.cal != nil {
+		action = "reloaded"
+	}
+	f.cal, f.err = cal, ""
+	logger.LogInfo(fmt.Sprintf("Holiday calendar: %s %s (%d events)", action, f.path, len(cal.Events)))
+}
+
+// fail 記錄載入失敗；同一個錯誤只記錄一次
+func (f *holidayFile) fail(err error) {
+	if msg := err.Error(); msg != f.err {
+		f.err = msg
+		logger.LogError("Holiday calendar: load failed, keeping previous events:", err)
+	}
+}
@@ -1,39 +1,37 @@
 package schedule
 
 import (
-	"strings"
 	"time"
 
 	"github.com/HanksJCTsai/goidleguard/internal/config"
 )
 
 func InitialScheduler(cfg *config.APPConfig) *Scheduler {
-	return &Scheduler{
+	s := &Scheduler{
 		Config:   cfg,
 		StopChan: make(chan struct{}),
 	}
+	if len(cfg.Holidays.Calendars) > 0 {
+		s.Holidays = NewHolidayCalendar(cfg.Holidays.Calendars)
+	}
+	return s
 }
 
+// CheckWorkTime 判斷 now 是否落在排程設定的工作時段內，且不在假日行事曆的事件期間。
+// 行事曆中沒有時區的事件依 workSchedule.timezone 解讀。
 func (s *Scheduler) CheckWorkTime(now time.Time) bool {
-	day := strings.ToLower(now.Weekday().String()) // 例如 "monday"
-	sessions, exists := s.Config.WorkSchedule[day]
-	if !exists || len(sessions) == 0 {
+	if !CheckWorkTime(s.Config, now) {
 		return false
 	}
-	for _, session := range sessions {
-		start, err := parseSessionTime(session.Start, now)
-		if err != nil {
-			continue
-		}
-		end, err := parseSessionTime(session.End, now)
-		if err != nil {
-			continue
-		}
-		if IsTimeInRange(now, start, end) {
-			return true
-		}
+	if s.Holidays == nil {
+		return true
+	}
+	loc, err := location(s.Config.Timezone, now.Location())
+	if err != nil {
+		return false
 	}
-	return false
+	_, holiday := s.Holidays.Holiday(now.In(loc))
+	return !holiday
 }
 
 func (s *Scheduler) ScheduleTask(task func()) {
@@ -41,20 +39,37 @@ func (s *Scheduler) ScheduleTask(task func()) {
 	go func() {
 		defer s.WG.Done()
 
-		ticker := time.NewTicker(s.Config.Scheduler.Interval)
-		defer ticker.Stop()
+		interval := s.Config.Scheduler.Interval
+		if interval <= 0 {
+			// 未設定排程間隔時，預設每分鐘觸發一次
+			interval = defaultInterval
+		}
+		timer := time.NewTimer(s.next(interval))
+		defer timer.Stop()
 
 		for {
 			select {
 			case <-s.StopChan:
 				return
-			case <-ticker.C:
+			case <-timer.C:
 				task()
+				timer.Reset(s.next(interval))
 			}
 		}
 	}()
 }
 
+// next 回傳下一次觸發前的等待時間；未設定 Jitter 時固定為 interval
+func (s *Scheduler) next(interval time.Duration) time.Duration {
+	if s.Jitter == nil {
+		return interval
+	}
+	if d := s.Jitter(interval); d > 0 {
+		return d
+	}
+	return interval
+}
+
 func (s *Scheduler) StopScheduler() {
 	close(s.StopChan)
 	s.WG.Wait()
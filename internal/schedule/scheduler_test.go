@@ -1,6 +1,8 @@
 package schedule
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -54,6 +56,58 @@ func TestCheckWorkTime(t *testing.T) {
 	}
 }
 
+func TestCheckWorkTime_Overnight(t *testing.T) {
+	// friday 22:00 開始的夜班延續到 saturday 06:00
+	cfg := &config.APPConfig{
+		WorkSchedule: config.WorkSchedule{
+			"friday": {{Start: "22:00", End: "06:00"}},
+		},
+	}
+	s := InitialScheduler(cfg)
+
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2025, time.April, 11, 23, 0, 0, 0, time.Local), true},  // friday 夜間
+		{time.Date(2025, time.April, 12, 5, 30, 0, 0, time.Local), true},  // saturday 凌晨屬於 friday 的時段
+		{time.Date(2025, time.April, 12, 6, 30, 0, 0, time.Local), false}, // 時段已結束
+		{time.Date(2025, time.April, 12, 23, 0, 0, 0, time.Local), false}, // saturday 沒有時段
+		{time.Date(2025, time.April, 11, 5, 30, 0, 0, time.Local), false}, // thursday 沒有夜班
+	} {
+		if got := s.CheckWorkTime(tt.at); got != tt.want {
+			t.Errorf("CheckWorkTime(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestCheckWorkTime_Timezone(t *testing.T) {
+	cfg := &config.APPConfig{
+		Timezone: "Asia/Taipei",
+		WorkSchedule: config.WorkSchedule{
+			"monday": {
+				{Start: "09:00", End: "18:00"},
+				{Start: "09:00", End: "10:00", Timezone: "America/New_York"},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2025, time.April, 7, 2, 0, 0, 0, time.UTC), true},    // 台北 monday 10:00
+		{time.Date(2025, time.April, 7, 11, 0, 0, 0, time.UTC), false},  // 台北 monday 19:00
+		{time.Date(2025, time.April, 6, 23, 0, 0, 0, time.UTC), false},  // 台北已是 monday 07:00，但尚未開始
+		{time.Date(2025, time.April, 7, 13, 30, 0, 0, time.UTC), true},  // 紐約 monday 09:30（EDT）
+		{time.Date(2025, time.April, 8, 13, 30, 0, 0, time.UTC), false}, // 紐約 tuesday 09:30
+	} {
+		if got := CheckWorkTime(cfg, tt.at); got != tt.want {
+			t.Errorf("CheckWorkTime(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
 func TestSchedulerScheduleTask(t *testing.T) {
 	// 測試 ScheduleTask 是否正確啟動 task
 	cfg := &config.APPConfig{
@@ -80,3 +134,151 @@ func TestSchedulerScheduleTask(t *testing.T) {
 	}
 	s.StopScheduler()
 }
+
+func TestSchedulerScheduleTask_Jitter(t *testing.T) {
+	cfg := &config.APPConfig{Scheduler: config.SchedulerConfig{Interval: time.Hour}}
+	s := InitialScheduler(cfg)
+
+	var intervals []time.Duration
+	s.Jitter = func(interval time.Duration) time.Duration {
+		intervals = append(intervals, interval)
+		return 10 * time.Millisecond
+	}
+	done := make(chan bool, 3)
+	s.ScheduleTask(func() { done <- true })
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected jittered interval to replace the configured one")
+		}
+	}
+	s.StopScheduler()
+	if intervals[0] != time.Hour {
+		t.Errorf("Expected Jitter to receive the configured interval, got %v", intervals[0])
+	}
+}
+
+func TestScheduler_CheckWorkTime_Holidays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	writeCalendar := func(body string, mtime time.Time) {
+		t.Helper()
+		data := "BEGIN:VCALENDAR\nVERSION:2.0\n" + body + "END:VCALENDAR\n"
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+	}
+	// 2025-04-07 monday 全天放假
+	writeCalendar("BEGIN:VEVENT\nUID:a\nSUMMARY:Company day\nDTSTART;VALUE=DATE:20250407\nEND:VEVENT\n",
+		time.Now().Add(-time.Hour))
+
+	cfg := &config.APPConfig{
+		Timezone: "Asia/Taipei",
+		WorkSchedule: config.WorkSchedule{
+			"monday":  {{Start: "09:00", End: "18:00"}},
+			"tuesday": {{Start: "09:00", End: "18:00"}},
+		},
+		Holidays: config.HolidaysConfig{Calendars: []string{path}},
+	}
+	s := InitialScheduler(cfg)
+
+	monday := time.Date(2025, time.April, 7, 2, 0, 0, 0, time.UTC)  // 台北 10:00
+	tuesday := time.Date(2025, time.April, 8, 2, 0, 0, 0, time.UTC) // 台北 10:00
+	if s.CheckWorkTime(monday) {
+		t.Error("Expected holiday to override monday work session")
+	}
+	if !s.CheckWorkTime(tuesday) {
+		t.Error("Expected tuesday to be work time")
+	}
+
+	// 檔案變更後重新載入：改為 tuesday 上午的請假
+	writeCalendar("BEGIN:VEVENT\nUID:b\nSUMMARY:PTO\nDTSTART;TZID=Asia/Taipei:20250408T090000\nDTEND;TZID=Asia/Taipei:20250408T120000\nEND:VEVENT\n",
+		time.Now())
+	if !s.CheckWorkTime(monday) {
+		t.Error("Expected monday to be work time after reload")
+	}
+	if s.CheckWorkTime(tuesday) {
+		t.Error("Expected reloaded PTO to override tuesday work session")
+	}
+
+	// 解析失敗時沿用上一次成功載入的內容
+	writeCalendar("BEGIN:VEVENT\nDTSTART:bogus\nEND:VEVENT\n", time.Now().Add(time.Hour))
+	if s.CheckWorkTime(tuesday) {
+		t.Error("Expected previous events to be kept after a failed reload")
+	}
+}
+
+func TestCheckWorkTime_Overrides(t *testing.T) {
+	weekday := []config.WorkSession{{Start: "09:00", End: "17:00"}}
+	cfg := &config.APPConfig{
+		WorkSchedule: config.WorkSchedule{
+			"monday": weekday, "tuesday": weekday, "wednesday": weekday, "thursday": weekday, "friday": weekday,
+		},
+		Overrides: config.ScheduleOverrides{
+			"2026-12-24":            {Sessions: []config.WorkSession{{Start: "08:00", End: "12:00"}}},
+			"2026-11-02":            {Off: true},
+			"2026-12-28/2026-12-30": {Mode: config.OverrideExtend, Sessions: []config.WorkSession{{Start: "20:00", End: "02:00"}}},
+			"2026-12-26":            {Sessions: []config.WorkSession{{Start: "10:00", End: "14:00"}}}, // saturday
+		},
+	}
+
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, time.December, 24, 9, 0, 0, 0, time.Local), true},   // 只工作上午
+		{time.Date(2026, time.December, 24, 13, 0, 0, 0, time.Local), false}, // 取代了 09:00–17:00
+		{time.Date(2026, time.November, 2, 10, 0, 0, 0, time.Local), false},  // monday 放假
+		{time.Date(2026, time.November, 3, 10, 0, 0, 0, time.Local), true},   // 隔天照常
+		{time.Date(2026, time.December, 29, 10, 0, 0, 0, time.Local), true},  // extend 保留原本的時段
+		{time.Date(2026, time.December, 29, 21, 0, 0, 0, time.Local), true},  // 並加上夜間時段
+		{time.Date(2026, time.December, 31, 1, 0, 0, 0, time.Local), true},   // 區間最後一天的夜間時段延續到隔天凌晨
+		{time.Date(2026, time.December, 31, 21, 0, 0, 0, time.Local), false}, // 區間之外
+		{time.Date(2026, time.December, 26, 11, 0, 0, 0, time.Local), true},  // saturday 加班
+	} {
+		if got := CheckWorkTime(cfg, tt.at); got != tt.want {
+			t.Errorf("CheckWorkTime(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestCheckWorkTime_CronWindows(t *testing.T) {
+	cfg := &config.APPConfig{
+		CronWindows: []string{
+			`"0 8 * * 1#1" for 9h`, // 每月第一個週一
+			"30 22 * * 5L for 8h",  // 每月最後一個週五的夜班
+		},
+		Overrides: config.ScheduleOverrides{"2026-12-07": {Off: true}},
+	}
+	check := func() {
+		t.Helper()
+		for _, tt := range []struct {
+			at   time.Time
+			want bool
+		}{
+			{time.Date(2026, time.November, 2, 12, 0, 0, 0, time.Local), true},  // 第一個週一
+			{time.Date(2026, time.November, 9, 12, 0, 0, 0, time.Local), false}, // 第二個週一
+			{time.Date(2026, time.November, 2, 17, 30, 0, 0, time.Local), false},
+			{time.Date(2026, time.November, 28, 5, 0, 0, 0, time.Local), true},  // 11/27 夜班延續到週六清晨
+			{time.Date(2026, time.December, 7, 12, 0, 0, 0, time.Local), false}, // overrides 設為放假
+		} {
+			if got := CheckWorkTime(cfg, tt.at); got != tt.want {
+				t.Errorf("CheckWorkTime(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		}
+	}
+
+	// 未經 ValidateConfig 的設定也要套用 cron 時窗
+	check()
+
+	windows, err := config.ParseCronWindows(cfg.CronWindows)
+	if err != nil {
+		t.Fatalf("ParseCronWindows failed: %v", err)
+	}
+	cfg.CronSchedule = windows
+	check()
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/HanksJCTsai/goidleguard/internal/config"
+	"github.com/HanksJCTsai/goidleguard/internal/cron"
 )
 
 func parseSessionTime(tStr string, now time.Time) (time.Time, error) {
@@ -16,23 +17,113 @@ func parseSessionTime(tStr string, now time.Time) (time.Time, error) {
 	return time.Date(now.Year(), now.Month(), now.Day(), paresd.Hour(), paresd.Minute(), 0, 0, now.Location()), nil
 }
 
+// sessionRange 回傳 day 當天開始的工作時段起訖時間；結束時間不晚於開始時間時
+// 視為跨越午夜的時段（例如 22:00–06:00），結束時間落在隔天。
+func sessionRange(session config.WorkSession, day time.Time) (time.Time, time.Time, error) {
+	start, err := parseSessionTime(session.Start, day)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseSessionTime(session.End, day)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !start.Before(end) {
+		end = time.Date(end.Year(), end.Month(), end.Day()+1, end.Hour(), end.Minute(), 0, 0, end.Location())
+	}
+	return start, end, nil
+}
+
 // IsTimeInRange 判斷 target 是否介於 start 與 end 之間。
 func IsTimeInRange(target, start, end time.Time) bool {
 	return target.After(start) && target.Before(end)
 }
 
+// CheckWorkTime 判斷 now 是否落在工作時段或 cron 工作時窗內。now 會先轉換到 workSchedule.timezone
+// （或時段自己的 timezone）再決定日期、星期與時刻；未設定時區時使用 now 本身的時區。
+// overrides 中涵蓋該日期的設定優先於星期的時段與當天開始的 cron 時窗：off 或 replace 時不使用它們。
+// 除了當天的時段，也檢查前一天跨越午夜的時段，凌晨的部分屬於前一天的排班。
 func CheckWorkTime(cfg *config.APPConfig, now time.Time) bool {
-	day := strings.ToLower(now.Weekday().String()) // 例如 "monday"
-	sessions, exists := cfg.WorkSchedule[day]
-	if !exists || len(sessions) == 0 {
+	base, err := location(cfg.Timezone, now.Location())
+	if err != nil {
 		return false
 	}
+	for day, sessions := range cfg.WorkSchedule {
+		onDay := func(d time.Time) bool {
+			if strings.ToLower(d.Weekday().String()) != day {
+				return false
+			}
+			o, ok := cfg.Overrides.Lookup(d)
+			return !ok || (!o.Off && o.Mode == config.OverrideExtend)
+		}
+		if anySessionActive(sessions, onDay, base, now) {
+			return true
+		}
+	}
+	for key, o := range cfg.Overrides {
+		first, last, err := config.ParseOverrideDates(key)
+		if err != nil {
+			continue
+		}
+		inRange := func(d time.Time) bool {
+			date := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+			return !date.Before(first) && !date.After(last)
+		}
+		if anySessionActive(o.Sessions, inRange, base, now) {
+			return true
+		}
+	}
+	return cronWindowActive(cfg, now.In(base))
+}
+
+// cronWindowActive 判斷 now 是否落在任一 cron 工作時窗內；開始日期被 overrides 設為 off 或 replace 的觸發不算。
+// 優先使用 ValidateConfig 已解析的 CronSchedule；未經驗證的設定則在此解析 CronWindows，無效的項目略過。
+func cronWindowActive(cfg *config.APPConfig, now time.Time) bool {
+	windows := cfg.CronSchedule
+	if windows == nil {
+		for _, entry := range cfg.CronWindows {
+			if w, err := cron.ParseWindow(entry); err == nil {
+				windows = append(windows, w)
+			}
+		}
+	}
+	for _, w := range windows {
+		active := false
+		w.Starts(now, func(start time.Time) bool {
+			o, ok := cfg.Overrides.Lookup(start)
+			active = !ok || (!o.Off && o.Mode == config.OverrideExtend)
+			return !active
+		})
+		if active {
+			return true
+		}
+	}
+	return false
+}
+
+// anySessionActive 判斷 now 是否落在任一 session 內；session 依自己的時區（預設 base）解讀，
+// onDay 決定某一天是否套用這些時段
+func anySessionActive(sessions []config.WorkSession, onDay func(time.Time) bool, base *time.Location, now time.Time) bool {
 	for _, session := range sessions {
-		start, err := parseSessionTime(session.Start, now)
+		loc, err := location(session.Timezone, base)
 		if err != nil {
 			continue
 		}
-		end, err := parseSessionTime(session.End, now)
+		if sessionActive(session, onDay, now.In(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionActive 判斷 now 是否落在 onDay 所接受日期的 session 內，包含前一天開始的跨夜時段
+func sessionActive(session config.WorkSession, onDay func(time.Time) bool, now time.Time) bool {
+	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+	for _, d := range []time.Time{now, yesterday} {
+		if !onDay(d) {
+			continue
+		}
+		start, end, err := sessionRange(session, d)
 		if err != nil {
 			continue
 		}
@@ -42,3 +133,11 @@ func CheckWorkTime(cfg *config.APPConfig, now time.Time) bool {
 	}
 	return false
 }
+
+// location 載入 IANA 時區名稱，空字串時回傳 fallback
+func location(name string, fallback *time.Location) (*time.Location, error) {
+	if name == "" {
+		return fallback, nil
+	}
+	return time.LoadLocation(name)
+}
@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"sync"
+	"time"
 
 	"github.com/HanksJCTsai/goidleguard/internal/config"
 )
@@ -10,4 +11,12 @@ type Scheduler struct {
 	Config   *config.APPConfig
 	StopChan chan struct{}
 	WG       sync.WaitGroup
+
+	// Jitter 若不為 nil，每次觸發後以其回傳值作為下一次的間隔，避免每次都在相同相位觸發
+	Jitter func(interval time.Duration) time.Duration
+
+	// Holidays 若不為 nil，行事曆事件期間視為非工作時間，優先於 workSchedule
+	Holidays *HolidayCalendar
 }
+
+const defaultInterval = time.Minute
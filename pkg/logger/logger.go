@@ -22,24 +22,27 @@ func InitLogger() {
 
 // LogInfo 輸出 Info 級別的日誌訊息。
 func LogInfo(v ...interface{}) {
-	loggerOnce.Do(func() {
-		InitLogger()
-	})
+	ensureLogger()
 	infoLogger.Println(v...)
 }
 
 // LogDebug 輸出 Debug 級別的日誌訊息。
 func LogDebug(v ...interface{}) {
-	loggerOnce.Do(func() {
-		InitLogger()
-	})
+	ensureLogger()
 	debugLogger.Println(v...)
 }
 
 // LogError 輸出 Error 級別的日誌訊息。
 func LogError(v ...interface{}) {
+	ensureLogger()
+	errorLogger.Println(v...)
+}
+
+// ensureLogger 在尚未初始化時以預設設定建立 logger，不覆蓋已設定的輸出。
+func ensureLogger() {
 	loggerOnce.Do(func() {
-		InitLogger()
+		if infoLogger == nil || debugLogger == nil || errorLogger == nil {
+			InitLogger()
+		}
 	})
-	errorLogger.Println(v...)
 }